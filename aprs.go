@@ -11,13 +11,39 @@ import "errors"
 // Errors.
 var (
 	ErrCallNotVerified = errors.New("callsign not verified")
+	ErrExtnInvalid     = errors.New("data extension is invalid")
 	ErrFrameBadControl = errors.New("frame Control Field not UI-frame")
 	ErrFrameBadProto   = errors.New("frame Protocol ID not no layer 3 protocol")
 	ErrFrameIncomplete = errors.New("frame incomplete")
 	ErrFrameInvalid    = errors.New("frame is invalid")
 	ErrFrameNoLast     = errors.New("frame incomplete or last path not set")
 	ErrFrameShort      = errors.New("frame too short (16-bytes minimum)")
+	ErrFreqDCS         = errors.New("frequency DCS code is not standard")
+	ErrFreqMhz         = errors.New("frequency is invalid (0-999.999 MHz)")
+	ErrFreqOffset      = errors.New("frequency offset is invalid (-9999 to +9999)")
+	ErrFreqRange       = errors.New("frequency range is invalid (0-999 miles)")
+	ErrFreqSquelch     = errors.New("frequency tone, CTCSS, and DCS are mutually exclusive")
+	ErrFreqTone        = errors.New("frequency tone is not a standard CTCSS tone")
+	ErrItemInvalid     = errors.New("item is invalid")
+	ErrItemName        = errors.New("item name is invalid (3-9 bytes, no '!' or '_')")
+	ErrLocatorInvalid  = errors.New("Maidenhead locator is invalid (2-10 bytes)")
+	ErrMicEDst         = errors.New("Mic-E destination address is invalid")
+	ErrMsgAddressee    = errors.New("message addressee is invalid (1-9 bytes)")
+	ErrMsgID           = errors.New("message ID is invalid (1-5 alphanumeric bytes)")
+	ErrMsgInvalid      = errors.New("message is invalid")
+	ErrMsgText         = errors.New("message text is invalid (67 bytes maximum, no '|', '~', or '{')")
+	ErrNMEAInvalid     = errors.New("NMEA sentence is invalid")
+	ErrObjInvalid      = errors.New("object is invalid")
+	ErrObjName         = errors.New("object name is invalid (1-9 bytes)")
+	ErrPosDataType     = errors.New("position data type is unknown")
+	ErrPosInvalid      = errors.New("position is invalid")
+	ErrPosShort        = errors.New("position too short")
 	ErrProtoScheme     = errors.New("protocol scheme is unknown")
+	ErrStatusInvalid   = errors.New("status is invalid")
+	ErrStatusText      = errors.New("status text is invalid (62 bytes maximum, no '|' or '~')")
+	ErrTimestamp       = errors.New("timestamp is invalid")
+	ErrTlmInvalid      = errors.New("telemetry is invalid")
+	ErrWxInvalid       = errors.New("weather report is invalid")
 )
 
 // SwName is the default software name.
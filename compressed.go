@@ -0,0 +1,168 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"math"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 9: Compressed Position Report Data Formats
+//
+// The compressed position data is always 13 bytes:
+//
+//	Sym Table ID | Compressed Lat | Compressed Long | Symbol Code | cs | Comp Type
+//	      1-byte |        4-bytes |         4-bytes |      1-byte |  2 |    1-byte
+//
+// All bytes except the symbol table and code are base-91 encoded by
+// adding 33 to each value.
+
+// The compression type byte has the following bit breakdown:
+//
+// Bit  7 6    | 5           | 4 3         | 2 1 0
+//     --------+-------------+-------------+--------
+//      Unused | GPS fix     | NMEA source | Origin
+//     --------+-------------+-------------+--------
+//      0      | 0 = old     | 00 = other  | 0-7
+//             | 1 = current | 01 = GLL    |
+//             |             | 10 = GGA    |
+//             |             | 11 = RMC    |
+
+const (
+	compFixCurrent = 0x20
+	compNMEAMask   = 0x18
+	compNMEAGGA    = 0x10
+	compNMEARMC    = 0x18
+	compSoftware   = 0x02
+)
+
+const compressedLen = 13
+
+// isCompressed returns true if s looks like it starts with compressed
+// position data rather than uncompressed.
+func isCompressed(s string) bool {
+	if len(s) < 1 {
+		return false
+	}
+	c := s[0]
+
+	return c == '/' || c == '\\' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'j')
+}
+
+// renderCompressed returns the rendered compressed position.  cs
+// must either be empty or the 3 byte course/speed, range, or altitude
+// with compression type.
+func renderCompressed(lat, lon float64, sym, cs string) string {
+	if len(sym) < 2 {
+		sym = "//" // default primary table, dot
+	}
+	if cs == "" {
+		cs = csNone()
+	}
+
+	// Overlay characters 0-9 are sent as a-j.
+	table := sym[0]
+	if table >= '0' && table <= '9' {
+		table = table - '0' + 'a'
+	}
+
+	y := int(380926.0 * (90.0 - lat))
+	x := int(190463.0 * (180.0 + lon))
+
+	return string(table) + encBase91(y, 4) + encBase91(x, 4) + string(sym[1]) + cs
+}
+
+// parseCompressed parses compressed position data and returns the
+// latitude, longitude, symbol, and the 3 byte cs and compression type.
+func parseCompressed(s string) (lat, lon float64, sym, cs string, err error) {
+	if len(s) < compressedLen {
+		err = ErrPosShort
+		return
+	}
+
+	y, ok := decBase91(s[1:5])
+	if !ok {
+		err = ErrPosInvalid
+		return
+	}
+	x, ok := decBase91(s[5:9])
+	if !ok {
+		err = ErrPosInvalid
+		return
+	}
+	lat = 90.0 - float64(y)/380926.0
+	lon = -180.0 + float64(x)/190463.0
+	if lat < -90.0 || lon > 180.0 {
+		err = ErrPosInvalid
+		return
+	}
+
+	// Overlay characters a-j are used in place of 0-9.
+	table := s[0]
+	if table >= 'a' && table <= 'j' {
+		table = table - 'a' + '0'
+	}
+	sym = string([]byte{table, s[9]})
+	cs = s[10:13]
+
+	return
+}
+
+// csNone returns cs bytes indicating there is no course/speed, range,
+// or altitude.
+func csNone() string {
+	return "  " + string(rune(compSoftware+33))
+}
+
+// csCourseSpeed returns cs bytes for the given course (in degrees)
+// and speed (in knots).
+func csCourseSpeed(course, speed int) string {
+	c := int(math.Round(float64(course%360)/4.0)) % 90
+	s := min(89, int(math.Round(math.Log(float64(max(0, speed))+1.0)/math.Log(1.08))))
+
+	return string([]byte{byte(c + 33), byte(s + 33), compFixCurrent | compNMEARMC | compSoftware + 33})
+}
+
+// csRange returns cs bytes for the given radio range (in miles).
+func csRange(miles int) string {
+	s := min(89, max(0, int(math.Round(math.Log(float64(miles)/2.0)/math.Log(1.08)))))
+
+	return string([]byte{'{', byte(s + 33), compFixCurrent | compSoftware + 33})
+}
+
+// csAltitude returns cs bytes for the given altitude (in feet).
+func csAltitude(feet int) string {
+	cs := min(91*91-1, max(0, int(math.Round(math.Log(float64(feet))/math.Log(1.002)))))
+
+	return encBase91(cs, 2) + string(rune(compFixCurrent|compNMEAGGA|compSoftware+33))
+}
+
+// parseCS parses the cs and compression type bytes.  Course is in
+// degrees, speed in knots, range in miles, and altitude in feet.  Only
+// one of course/speed, range, or altitude is ever set and ok is false if
+// none are.
+func parseCS(cs string) (course, speed, rng, alt int, ok bool) {
+	if len(cs) != 3 || cs[0] == ' ' {
+		return
+	}
+	c, s, t := int(cs[0])-33, int(cs[1])-33, int(cs[2])-33
+	if c < 0 || s < 0 || s > 90 || t < 0 {
+		return
+	}
+
+	switch {
+	case t&compNMEAMask == compNMEAGGA:
+		alt = int(math.Round(math.Pow(1.002, float64(c*91+s))))
+	case c <= 89:
+		course = c * 4
+		speed = int(math.Round(math.Pow(1.08, float64(s)) - 1.0))
+	case cs[0] == '{':
+		rng = int(math.Round(2.0 * math.Pow(1.08, float64(s))))
+	default:
+		return
+	}
+
+	return course, speed, rng, alt, true
+}
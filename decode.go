@@ -0,0 +1,185 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"strings"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 5: APRS Data in the AX.25 Information Field
+
+// Capabilities represents a station capabilities report as a map of
+// capability tokens to their (optional) values.
+type Capabilities map[string]string
+
+// Query represents a general APRS query, such as ?APRS? or ?IGATE?.
+type Query struct {
+	Type   string // query type (e.g. "APRS")
+	Target string // optional target footprint
+}
+
+// ThirdParty represents a third-party (tunneled or gated) frame.
+type ThirdParty struct {
+	Frame Frame
+}
+
+// UserDefined represents user-defined data.
+type UserDefined struct {
+	ID   byte   // user ID, usually assigned by the APRS author
+	Type byte   // user-defined packet type
+	Data string // user-defined data
+}
+
+// Unknown represents an information field that isn't understood, along
+// with its data type identifier.
+type Unknown struct {
+	Type byte
+	Text string
+}
+
+// Decode returns a typed value for the information field based on its
+// data type identifier:
+//
+//	! = @ /   PositionReport, or Wx for the weather station symbol
+//	[         PositionReport from an obsolete grid square
+//	` '       MicE
+//	;         Object
+//	)         Item
+//	:         Message, or TelemetryParm, TelemetryUnit,
+//	          TelemetryEqns, or TelemetryBits for definitions
+//	>         Status
+//	T         Telemetry
+//	<         Capabilities
+//	?         Query
+//	}         ThirdParty
+//	{         UserDefined
+//	$         NMEA
+//	_         Wx
+//
+// Anything else is returned as Unknown.
+func (f Frame) Decode() (any, error) {
+	if len(f.Text) < 1 {
+		return Unknown{}, nil
+	}
+
+	switch f.Text[0] {
+	case '!', '=', '/', '@':
+		p := PositionReport{}
+		err := p.FromString(f.Text)
+		if err == nil && len(p.Symbol) == 2 && p.Symbol[1] == '_' {
+			w := Wx{}
+			err = w.FromString(f.Text)
+			return w, err
+		}
+		return p, err
+	case '[':
+		p := PositionReport{}
+		err := p.FromString(f.Text)
+		return p, err
+	case '`', '\'':
+		m := MicE{}
+		err := m.FromFrame(f)
+		return m, err
+	case ';':
+		o := Object{}
+		err := o.FromString(f.Text)
+		return o, err
+	case ')':
+		i := Item{}
+		err := i.FromString(f.Text)
+		return i, err
+	case ':':
+		m := Message{}
+		err := m.FromString(f.Text)
+		if err == nil && isTlmMsg(m.Text) {
+			return decodeTlmMsg(f.Text, m.Text[:4])
+		}
+		return m, err
+	case '>':
+		st := Status{}
+		err := st.FromString(f.Text)
+		return st, err
+	case 'T':
+		if strings.HasPrefix(f.Text, "T#") {
+			t := Telemetry{}
+			err := t.FromString(f.Text)
+			return t, err
+		}
+	case '<':
+		return parseCapabilities(f.Text[1:]), nil
+	case '?':
+		return parseQuery(f.Text[1:]), nil
+	case '}':
+		tp := ThirdParty{}
+		err := tp.Frame.FromString(f.Text[1:])
+		return tp, err
+	case '{':
+		if len(f.Text) >= 3 {
+			return UserDefined{ID: f.Text[1], Type: f.Text[2], Data: f.Text[3:]}, nil
+		}
+	case '$':
+		n := NMEA{}
+		err := n.FromString(f.Text)
+		return n, err
+	case '_':
+		w := Wx{}
+		err := w.FromString(f.Text)
+		return w, err
+	}
+
+	// A '!' may appear anywhere within the first 40 characters of
+	// the information field, such as after a beacon text.
+	if i := strings.IndexByte(f.Text, '!'); i > 0 && i < 40 {
+		p := PositionReport{}
+		if err := p.FromString(f.Text[i:]); err == nil {
+			return p, nil
+		}
+	}
+
+	return Unknown{Type: f.Text[0], Text: f.Text}, nil
+}
+
+// decodeTlmMsg returns the typed telemetry definition message.
+func decodeTlmMsg(s, typ string) (any, error) {
+	switch typ {
+	case "PARM":
+		tp := TelemetryParm{}
+		err := tp.FromString(s)
+		return tp, err
+	case "UNIT":
+		tu := TelemetryUnit{}
+		err := tu.FromString(s)
+		return tu, err
+	case "EQNS":
+		te := TelemetryEqns{}
+		err := te.FromString(s)
+		return te, err
+	default:
+		tb := TelemetryBits{}
+		err := tb.FromString(s)
+		return tb, err
+	}
+}
+
+// parseCapabilities parses comma separated capability tokens.
+func parseCapabilities(s string) Capabilities {
+	c := Capabilities{}
+	for tok := range strings.SplitSeq(s, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(tok), "=")
+		if k != "" {
+			c[k] = v
+		}
+	}
+
+	return c
+}
+
+// parseQuery parses a general query of the form Query?Target.
+func parseQuery(s string) Query {
+	typ, target, _ := strings.Cut(s, "?")
+
+	return Query{Type: typ, Target: target}
+}
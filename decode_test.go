@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameDecode(t *testing.T) {
+	for _, test := range []struct {
+		frame string
+		want  any
+	}{
+		{"N0CALL>APRS:!4406.50N/10756.32Wj", PositionReport{}},
+		{"N0CALL>APRS:=/5L!!<*e7>7P[", PositionReport{}},
+		{"N0CALL>APRS:Beacon text !4406.50N/10756.32Wj", PositionReport{}},
+		{"N0CALL>APRS:[IO91SX] 35 miles NNW of London", PositionReport{}},
+		{"N0CALL>S32UVT:`(_fn\"Oj/", MicE{}},
+		{"N0CALL>APRS:;LEADER   *092345z4903.50N/07201.75W>088/036", Object{}},
+		{"N0CALL>APRS:)AID #2!4903.50N/07201.75WA", Item{}},
+		{"N0CALL>APRS::WU2Z     :Testing{003", Message{}},
+		{"N0CALL>APRS:>Net Control Center", Status{}},
+		{"N0CALL>APRS:T#005,199,000,255,073,123,01101001", Telemetry{}},
+		{"N0CALL>APRS:<IGATE,MSG_CNT=30,LOC_CNT=3", Capabilities{}},
+		{"N0CALL>APRS:?APRS?", Query{}},
+		{"N0CALL>APRS:}N0NE>APRS,TCPIP,N0CALL*:>Hello", ThirdParty{}},
+		{"N0CALL>APRS:{Q1qwerty", UserDefined{}},
+		{"N0CALL>APRS:$GPRMC,063909,A,3349.4302,N,11700.3721,W,43.022,89.3,291099,13.6,E*52", NMEA{}},
+		{"N0CALL>APRS:_10090556c220s004g005t077r000p000P000h50b09900wRSW", Wx{}},
+		{"N0CALL>APRS:!4903.50N/07201.75W_220/004g005t077", Wx{}},
+		{"N0CALL>APRS:,Test", Unknown{}},
+	} {
+		f := Frame{}
+		err := f.FromString(test.frame)
+		assert.Nil(t, err, "Frame %s", test.frame)
+		p, err := f.Decode()
+		assert.Nil(t, err, "Decode %s", test.frame)
+		assert.IsType(t, test.want, p, "Decode %s", test.frame)
+	}
+}
+
+func TestFrameDecodeValues(t *testing.T) {
+	a := assert.New(t)
+
+	decode := func(s string) any {
+		f := Frame{}
+		a.Nil(f.FromString(s), "Frame %s", s)
+		p, err := f.Decode()
+		a.Nil(err, "Decode %s", s)
+		return p
+	}
+
+	o := decode("N0CALL>APRS:;LEADER   _092345z4903.50N/07201.75W>088/036").(Object)
+	a.Equal("LEADER", o.Name, "Object name")
+	a.True(o.Killed, "Object killed")
+	a.Equal("088/036", o.Extn, "Object extension")
+
+	i := decode("N0CALL>APRS:)AID #2!4903.50N/07201.75WA").(Item)
+	a.Equal("AID #2", i.Name, "Item name")
+	a.False(i.Killed, "Item killed")
+	a.Equal("/A", i.Symbol, "Item symbol")
+
+	m := decode("N0CALL>APRS::WU2Z     :Testing{003").(Message)
+	a.Equal(Message{Addressee: "WU2Z", Text: "Testing", ID: "003"}, m, "Message")
+
+	st := decode("N0CALL>APRS:>092345zNet Control Center").(Status)
+	a.Equal("Net Control Center", st.Text, "Status text")
+	a.Equal(23, st.Timestamp.Hour(), "Status timestamp")
+
+	tlm := decode("N0CALL>APRS:T#005,199,000,255,073,123,01101001 hi").(Telemetry)
+	a.Equal(5, tlm.Seq, "Telemetry sequence")
+	a.Equal([5]float64{199, 0, 255, 73, 123}, tlm.Analog, "Telemetry analog")
+	a.Equal([8]bool{false, true, true, false, true, false, false, true}, tlm.Digital, "Telemetry digital")
+	a.Equal(" hi", tlm.Comment, "Telemetry comment")
+
+	c := decode("N0CALL>APRS:<IGATE,MSG_CNT=30,LOC_CNT=3").(Capabilities)
+	a.Equal(Capabilities{"IGATE": "", "MSG_CNT": "30", "LOC_CNT": "3"}, c, "Capabilities")
+
+	q := decode("N0CALL>APRS:?APRS?").(Query)
+	a.Equal(Query{Type: "APRS"}, q, "Query")
+
+	tp := decode("N0CALL>APRS:}N0NE>APRS,TCPIP,N0CALL*:>Hello").(ThirdParty)
+	a.Equal("N0NE", tp.Frame.Src.Call, "Third-party source")
+	a.Equal(">Hello", tp.Frame.Text, "Third-party text")
+
+	u := decode("N0CALL>APRS:{Q1qwerty").(UserDefined)
+	a.Equal(UserDefined{ID: 'Q', Type: '1', Data: "qwerty"}, u, "User-defined")
+
+	_, err := Frame{Text: "!4406.50N/107"}.Decode()
+	a.NotNil(err, "Invalid position")
+}
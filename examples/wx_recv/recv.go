@@ -14,7 +14,14 @@ func main() {
 	defer cancel()
 	fc := aprs.RecvIS(ctx, "rotate.aprs.net:14580", aprs.Addr{Call: "N0CALL"}, -1, "t/w")
 	for f := range fc {
-		log.Println(f)
+		v, err := f.Decode()
+		if err != nil {
+			log.Printf("Decode error: %s: %s", err, f)
+			continue
+		}
+		if w, ok := v.(aprs.Wx); ok {
+			log.Printf("%s: temp=%d humidity=%d wind=%d/%d", f.Src, w.Temp, w.Humidity, w.WindDir, w.WindSpeed)
+		}
 	}
 	log.Println("Close")
 }
@@ -0,0 +1,259 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CSExtn is a decoded course/speed data extension with optional direction
+// finding bearing and Number/Range/Quality.
+type CSExtn struct {
+	Course  int   // degrees, 0 if unknown
+	Speed   int   // knots
+	Bearing int   // DF bearing in degrees
+	NRQ     DFNRQ // DF Number/Range/Quality
+}
+
+// DFNRQ is a decoded DF Number/Range/Quality.
+type DFNRQ struct {
+	Hits      int  // hits per 8, 0 if the report is meaningless
+	Manual    bool // bearing was reported manually
+	Range     int  // miles
+	Beamwidth int  // degrees, 0 if useless
+}
+
+// dfBeamwidths are the DF beamwidths, in degrees, for each quality.
+var dfBeamwidths = [10]int{0, 240, 120, 64, 32, 16, 8, 4, 2, 1}
+
+// parseDFNRQ returns the DF Number/Range/Quality from its 3 digits.
+func parseDFNRQ(s string) (nrq DFNRQ, err error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			err = ErrExtnInvalid
+			return
+		}
+	}
+
+	n, r, q := int(s[0]-'0'), int(s[1]-'0'), int(s[2]-'0')
+	if n == 9 {
+		nrq.Manual = true
+		n = 8
+	}
+	nrq.Hits = n
+	nrq.Range = 1 << r
+	nrq.Beamwidth = dfBeamwidths[q]
+
+	return
+}
+
+// DSExtn is a decoded wind direction/speed data extension, which weather
+// stations use in place of course/speed.
+type DSExtn struct {
+	Dir   int // degrees, 0 if unknown
+	Speed int // mph
+}
+
+// PHGExtn is a decoded station power/effective antenna height/gain/
+// directivity data extension.
+type PHGExtn struct {
+	Power  int // watts
+	Height int // feet
+	Gain   int // dB
+	Dir    int // degrees, 0 for omni
+}
+
+// RNGExtn is a decoded pre-calculated radio range data extension.
+type RNGExtn struct {
+	Miles int
+}
+
+// DFSExtn is a decoded DF signal strength/effective antenna height/gain/
+// directivity data extension.
+type DFSExtn struct {
+	Strength int // S-points
+	Height   int // feet
+	Gain     int // dB
+	Dir      int // degrees, 0 for omni
+}
+
+// AreaType is an area object shape.
+type AreaType int
+
+// Area object shapes.
+const (
+	AreaCircle AreaType = iota
+	AreaLineRight
+	AreaEllipse
+	AreaTriangle
+	AreaBox
+	AreaCircleFilled
+	AreaLineLeft
+	AreaEllipseFilled
+	AreaTriangleFilled
+	AreaBoxFilled
+)
+
+// Filled returns true if the shape is color-filled.
+func (t AreaType) Filled() bool {
+	return t >= AreaCircleFilled && t != AreaLineLeft
+}
+
+// Line returns true if the shape is a line, which may have a width.
+func (t AreaType) Line() bool {
+	return t == AreaLineRight || t == AreaLineLeft
+}
+
+// AreaColor is an area object color.
+type AreaColor int
+
+// Area object colors.  The first 8 are high intensity and the rest are
+// low intensity.
+const (
+	AreaBlack AreaColor = iota
+	AreaBlue
+	AreaGreen
+	AreaCyan
+	AreaRed
+	AreaViolet
+	AreaYellow
+	AreaGray
+	AreaBlackLow
+	AreaBlueLow
+	AreaGreenLow
+	AreaCyanLow
+	AreaRedLow
+	AreaVioletLow
+	AreaYellowLow
+	AreaGrayLow
+)
+
+// AreaExtn is a decoded area object descriptor data extension.  The
+// object position is the center of circles and ellipses and the
+// upper-left corner of the other shapes; the offsets extend down and
+// across from it.
+type AreaExtn struct {
+	Type      AreaType
+	Color     AreaColor
+	LatOffset float64 // degrees
+	LonOffset float64 // degrees
+	Width     int     // line width in miles
+}
+
+// reArea matches an area object descriptor, including the 1 separator
+// used for colors 10-15 which isn't a valid course/speed, and an optional
+// line width.
+var reArea = regexp.MustCompile(`^[0-9]{3}[/1][0-9]{3}(?:\{[0-9]{1,3}\})?`)
+
+// isArea returns true if the report uses the area object symbol, in which
+// case the data extension is an area object descriptor.
+func (p *PositionReport) isArea() bool {
+	return p.Symbol == `\l`
+}
+
+// DecodeExtn returns the data extension as a CSExtn, DSExtn, PHGExtn,
+// RNGExtn, DFSExtn, or AreaExtn.  A course/speed is interpreted as wind
+// for weather stations and as an area object descriptor for area objects.
+// It returns nil if there's no data extension.
+func (p *PositionReport) DecodeExtn() (any, error) {
+	e := p.Extn
+	switch {
+	case e == "":
+		return nil, nil
+	case len(e) < 7:
+		return nil, ErrExtnInvalid
+	case p.isArea():
+		return parseAreaExtn(e)
+	case strings.HasPrefix(e, "PHG"):
+		power, height, gain, dir, err := parsePHGD(e[3:7])
+		return PHGExtn{Power: power * power, Height: height, Gain: gain, Dir: dir}, err
+	case strings.HasPrefix(e, "DFS"):
+		str, height, gain, dir, err := parsePHGD(e[3:7])
+		return DFSExtn{Strength: str, Height: height, Gain: gain, Dir: dir}, err
+	case strings.HasPrefix(e, "RNG"):
+		miles, err := strconv.Atoi(e[3:7])
+		if err != nil {
+			return nil, ErrExtnInvalid
+		}
+		return RNGExtn{Miles: miles}, nil
+	case e[3] == '/':
+		a, b := parseExtnValue(e[0:3]), parseExtnValue(e[4:7])
+		if len(p.Symbol) > 1 && p.Symbol[1] == '_' {
+			return DSExtn{Dir: a, Speed: b}, nil
+		}
+		cs := CSExtn{Course: a, Speed: b}
+		if len(e) >= 15 {
+			cs.Bearing = parseExtnValue(e[8:11])
+			nrq, err := parseDFNRQ(e[12:15])
+			if err != nil {
+				return nil, err
+			}
+			cs.NRQ = nrq
+		}
+		return cs, nil
+	}
+
+	return nil, ErrExtnInvalid
+}
+
+// parseExtnValue returns the 3 digit numeric data extension value or, if
+// it's unknown (dots or spaces), 0.
+func parseExtnValue(s string) int {
+	v, _ := strconv.Atoi(strings.TrimSpace(s))
+	return v
+}
+
+// parsePHGD returns the first value, the height in feet, the gain in dB,
+// and the directivity in degrees from PHG or DFS codes.
+func parsePHGD(s string) (v, height, gain, dir int, err error) {
+	if s[0] < '0' || s[0] > '9' || s[1] < '0' || s[2] < '0' || s[2] > '9' || s[3] < '0' || s[3] > '8' {
+		err = ErrExtnInvalid
+		return
+	}
+
+	// The height code may be any ASCII character 0 and above.
+	v = int(s[0] - '0')
+	height = 10 << (s[1] - '0')
+	gain = int(s[2] - '0')
+	dir = int(s[3]-'0') * 45
+
+	return
+}
+
+// parseAreaExtn returns the area object descriptor from a Tyy/Cxx data
+// extension.
+func parseAreaExtn(e string) (a AreaExtn, err error) {
+	if !reArea.MatchString(e) {
+		err = ErrExtnInvalid
+		return
+	}
+
+	a.Type = AreaType(e[0] - '0')
+	a.Color = AreaColor(e[4] - '0')
+	if e[3] == '1' {
+		a.Color += 10
+	}
+	// Offsets are the square root of hundredths of a degree.
+	yy, xx := int(e[1]-'0')*10+int(e[2]-'0'), int(e[5]-'0')*10+int(e[6]-'0')
+	a.LatOffset = float64(yy*yy) / 100.0
+	a.LonOffset = float64(xx*xx) / 100.0
+
+	if w, ok := strings.CutPrefix(e[7:], "{"); ok {
+		a.Width, _ = strconv.Atoi(strings.TrimSuffix(w, "}"))
+	}
+
+	return
+}
+
+// Range returns the estimated radio range in miles from the power,
+// height, and gain.
+func (e PHGExtn) Range() float64 {
+	gain := math.Pow(10, float64(e.Gain)/10.0)
+
+	return math.Sqrt(2.0 * float64(e.Height) * math.Sqrt(float64(e.Power)/10.0*gain/2.0))
+}
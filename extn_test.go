@@ -0,0 +1,127 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExamplePHGExtn_Range() {
+	p := PositionReport{}
+	if err := p.FromString("!4903.50N/07201.75W#PHG5132"); err != nil {
+		return
+	}
+	e, _ := p.DecodeExtn()
+	phg := e.(PHGExtn)
+	fmt.Printf("%d W %d ft %d dB %d deg %.1f mi\n", phg.Power, phg.Height, phg.Gain, phg.Dir, phg.Range())
+
+	// Output:
+	// 25 W 20 ft 3 dB 90 deg 7.9 mi
+}
+
+func TestDecodeExtn(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		s    string
+		want any
+	}{
+		{"!4903.50N/07201.75W>088/036", CSExtn{Course: 88, Speed: 36}},
+		{"!4903.50N/07201.75W>.../...", CSExtn{}},
+		{"!4903.50N/07201.75W\\088/036/270/729", CSExtn{Course: 88, Speed: 36, Bearing: 270, NRQ: DFNRQ{Hits: 7, Range: 4, Beamwidth: 1}}},
+		{"!4903.50N/07201.75W\\088/036/270/953", CSExtn{Course: 88, Speed: 36, Bearing: 270, NRQ: DFNRQ{Hits: 8, Manual: true, Range: 32, Beamwidth: 64}}},
+		{"!4903.50N/07201.75W_220/004g005t077", DSExtn{Dir: 220, Speed: 4}},
+		{"!4903.50N/07201.75W#PHG7:50/", PHGExtn{Power: 49, Height: 10240, Gain: 5, Dir: 0}},
+		{"!4903.50N/07201.75W#RNG0050", RNGExtn{Miles: 50}},
+		{"!4903.50N/07201.75W\\DFS2360", DFSExtn{Strength: 2, Height: 80, Gain: 6, Dir: 0}},
+		{"!4903.50N\\07201.75Wl715/427", AreaExtn{Type: 7, Color: 4, LatOffset: 2.25, LonOffset: 7.29}},
+		{"!4903.50N\\07201.75Wl1081141", AreaExtn{Type: 1, Color: 11, LatOffset: 0.64, LonOffset: 16.81}},
+		{"!4903.50N/07201.75W#", nil},
+	}
+
+	for _, test := range tests {
+		p := PositionReport{}
+		a.Nil(p.FromString(test.s), "Valid position %s", test.s)
+		got, err := p.DecodeExtn()
+		a.Nil(err, "Valid data extension %s", test.s)
+		a.Equal(test.want, got, "Data extension %s", test.s)
+	}
+
+	for _, e := range []string{"PHG", "PHGA132", "RNGABCD", "AB/CD/E"} {
+		p := PositionReport{Extn: e}
+		_, err := p.DecodeExtn()
+		a.Equal(ErrExtnInvalid, err, "Invalid data extension %s", e)
+	}
+}
+
+func TestExtnRoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	p := PositionReport{}
+	p.CSExtension(88, 36, 270, 729)
+	got, _ := p.DecodeExtn()
+	a.Equal(CSExtn{Course: 88, Speed: 36, Bearing: 270, NRQ: DFNRQ{Hits: 7, Range: 4, Beamwidth: 1}}, got, "CSE/SPD with DF")
+
+	p = PositionReport{}
+	p.PHGExtension(5, 3, 2, '1')
+	got, _ = p.DecodeExtn()
+	a.Equal(PHGExtn{Power: 25, Height: 20, Gain: 3, Dir: 90}, got, "PHG")
+
+	p.RNGExtension(25)
+	got, _ = p.DecodeExtn()
+	a.Equal(RNGExtn{Miles: 25}, got, "RNG")
+
+	p.DFSExtension(4, 0, 8, '3')
+	got, _ = p.DecodeExtn()
+	a.Equal(DFSExtn{Strength: 4, Height: 80, Gain: 0, Dir: 360}, got, "DFS")
+}
+
+func ExamplePositionReport_AreaExtension() {
+	o := Object{Name: "CLOSURE"}
+	o.Timestamp = time.Date(2016, time.November, 5, 20, 35, 0, 0, time.UTC)
+	o.Lat, o.Lon = 35.7, -78.7
+	o.AreaExtension(AreaLineRight, AreaRedLow, 0.04, 0.09, 2)
+	o.Comment = "Road closed"
+	fmt.Println(o.String())
+
+	// Output:
+	// ;CLOSURE  *052035z3542.00N\07842.00Wl1021203{2}Road closed
+}
+
+func TestAreaExtn(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		t        AreaType
+		c        AreaColor
+		lat, lon float64
+		width    int
+		extn     string
+		want     AreaExtn
+		filled   bool
+	}{
+		{AreaCircle, AreaRed, 0.25, 0.25, 0, "005/405", AreaExtn{AreaCircle, AreaRed, 0.25, 0.25, 0}, false},
+		{AreaBoxFilled, AreaGreenLow, 1.0, 2.25, 0, "9101015", AreaExtn{AreaBoxFilled, AreaGreenLow, 1.0, 2.25, 0}, true},
+		{AreaLineLeft, AreaYellow, 0.01, 0.16, 15, "601/604{15}", AreaExtn{AreaLineLeft, AreaYellow, 0.01, 0.16, 15}, false},
+		{AreaTriangle, AreaGrayLow, 0.5, 0.5, 5, "3071507", AreaExtn{AreaTriangle, AreaGrayLow, 0.49, 0.49, 0}, false},
+	}
+
+	for _, test := range tests {
+		p := PositionReport{Lat: 35.7, Lon: -78.7}
+		p.AreaExtension(test.t, test.c, test.lat, test.lon, test.width)
+		a.Equal(test.extn, p.Extn, "Area extension")
+		a.Equal(test.filled, test.t.Filled(), "Filled %s", test.extn)
+
+		got := PositionReport{}
+		a.Nil(got.FromString(p.String()), "Valid area %s", p.String())
+		e, err := got.DecodeExtn()
+		a.Nil(err, "Valid area extension %s", test.extn)
+		a.Equal(test.want, e, "Area %s", test.extn)
+	}
+}
@@ -2,55 +2,52 @@ package aprs
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // Freq contains an APRS FreqSpec frequency report
 // http://www.aprs.org/info/freqspec.txt
 type Freq struct {
 	Mhz    float64 // Frequency in Mhz
-	Tone   int     // Tone in hz
+	Tone   int     // Tone in hz, truncated (88 for 88.5)
 	CTCSS  int     // ctcss (mutually exclusive with tone and dcs)
-	DCS    int     // dcs (mutually exclusive with tone and ctcss)
-	Offset int     // +/- offset in mhz
+	DCS    int     // dcs code digits (23 for 023; mutually exclusive with tone and ctcss)
+	Offset int     // +/- offset as sent, in 10 khz steps (-60 for -600 khz)
 	Range  int     // range in miles
 	Narrow bool    // defaults to false for wideband
 }
 
-// Render renders the frequency struct into a string
+// Render renders the frequency struct into a string.  Tone, CTCSS, and
+// DCS are mutually exclusive so only the first one set, in that order,
+// is rendered.
 func (f *Freq) Render() string {
 	// set the frequency
 	out := fmt.Sprintf("%07.03fMHz ", f.Mhz)
 
-	// check for a tone
-	if f.Tone > 0 {
-		t := "T"
-		if f.Narrow {
-			t = "t"
-		}
-		out += t + z3p(f.Tone) + " "
-	}
-
-	// check for CTCSS
-	if f.CTCSS > 0 {
-		c := "C"
-		if f.Narrow {
-			c = "c"
-		}
-		out += c + z3p(f.CTCSS) + " "
+	// check for a tone, CTCSS, or DCS
+	var sq string
+	var code int
+	switch {
+	case f.Tone > 0:
+		sq, code = "T", f.Tone
+	case f.CTCSS > 0:
+		sq, code = "C", f.CTCSS
+	case f.DCS > 0:
+		sq, code = "D", f.DCS
 	}
-
-	// check for DCS
-	if f.DCS > 0 {
-		d := "D"
+	if sq != "" {
 		if f.Narrow {
-			d = "d"
+			sq = strings.ToLower(sq)
 		}
-		out += d + z3p(f.DCS) + " "
+		out += sq + z3p(code) + " "
 	}
 
 	// check for offset
 	if f.Offset != 0 {
-		out += z3p(f.Offset) + " "
+		out += fmt.Sprintf("%+04d ", f.Offset)
 	}
 
 	// check range
@@ -59,3 +56,181 @@ func (f *Freq) Render() string {
 	}
 	return out
 }
+
+// reFreq matches the frequency which begins a FreqSpec comment.
+var reFreq = regexp.MustCompile(`^(\d{3}\.\d{2,3})MHz(?: |$)`)
+
+// reFreqParam matches an optional FreqSpec parameter following the
+// frequency: a tone, CTCSS, or DCS code; an offset, which older
+// versions of Render sent unsigned when positive; or a range.
+var reFreqParam = regexp.MustCompile(`^(?:([TtCcDd])(\d{3}|off)|([+-]\d{3,4}|\d{3})|R(\d{2,3})([mk]))(?: |$)`)
+
+// ParseFreq extracts a FreqSpec frequency, and any tone, CTCSS, DCS,
+// offset, and range parameters, from the beginning of a comment.  It
+// returns the frequency and the remaining comment text or, if the
+// comment doesn't begin with a frequency, nil and the unmodified comment.
+//
+// Both the original FreqSpec (FFF.FFFMHz Tnnn RnnM) and the newer
+// repeater object (FFF.FFFMHz Tnnn +xxx) formats are supported.
+// Lowercase tone, CTCSS, and DCS codes indicate a narrowband channel.
+func ParseFreq(s string) (*Freq, string) {
+	m := reFreq.FindStringSubmatch(s)
+	if m == nil {
+		return nil, s
+	}
+
+	f := &Freq{}
+	f.Mhz, _ = strconv.ParseFloat(m[1], 64)
+	s = s[len(m[0]):]
+
+	for {
+		m = reFreqParam.FindStringSubmatch(s)
+		if m == nil {
+			break
+		}
+		s = s[len(m[0]):]
+
+		switch {
+		case m[1] != "":
+			// Toff means no tone
+			v, _ := strconv.Atoi(m[2])
+			switch strings.ToUpper(m[1]) {
+			case "T":
+				f.Tone = v
+			case "C":
+				f.CTCSS = v
+			case "D":
+				f.DCS = v
+			}
+			if m[1] == strings.ToLower(m[1]) {
+				f.Narrow = true
+			}
+		case m[3] != "":
+			f.Offset, _ = strconv.Atoi(m[3])
+		case m[4] != "":
+			f.Range, _ = strconv.Atoi(m[4])
+			if m[5] == "k" {
+				// kilometers to miles
+				f.Range = int(math.Round(float64(f.Range) * 0.621371))
+			}
+		}
+	}
+
+	return f, s
+}
+
+// ctcssTones are the standard CTCSS tones, truncated to whole hz as
+// they're sent in a FreqSpec.
+var ctcssTones = map[int]bool{
+	67: true, 69: true, 71: true, 74: true, 77: true, 79: true, 82: true,
+	85: true, 88: true, 91: true, 94: true, 97: true, 100: true, 103: true,
+	107: true, 110: true, 114: true, 118: true, 123: true, 127: true,
+	131: true, 136: true, 141: true, 146: true, 151: true, 156: true,
+	159: true, 162: true, 165: true, 167: true, 171: true, 173: true,
+	177: true, 179: true, 183: true, 186: true, 189: true, 192: true,
+	196: true, 199: true, 203: true, 206: true, 210: true, 218: true,
+	225: true, 229: true, 233: true, 241: true, 250: true, 254: true,
+}
+
+// dcsCodes are the standard DCS codes.  The octal code digits are
+// stored as a decimal number, as they're sent in a FreqSpec.
+var dcsCodes = map[int]bool{
+	23: true, 25: true, 26: true, 31: true, 32: true, 36: true, 43: true,
+	47: true, 51: true, 53: true, 54: true, 65: true, 71: true, 72: true,
+	73: true, 74: true, 114: true, 115: true, 116: true, 122: true,
+	125: true, 131: true, 132: true, 134: true, 143: true, 145: true,
+	152: true, 155: true, 156: true, 162: true, 165: true, 172: true,
+	174: true, 205: true, 212: true, 223: true, 225: true, 226: true,
+	243: true, 244: true, 245: true, 246: true, 251: true, 252: true,
+	255: true, 261: true, 263: true, 265: true, 266: true, 271: true,
+	274: true, 306: true, 311: true, 315: true, 325: true, 331: true,
+	332: true, 343: true, 346: true, 351: true, 356: true, 364: true,
+	365: true, 371: true, 411: true, 412: true, 413: true, 423: true,
+	431: true, 432: true, 445: true, 446: true, 452: true, 454: true,
+	455: true, 462: true, 464: true, 465: true, 466: true, 503: true,
+	506: true, 516: true, 523: true, 526: true, 532: true, 546: true,
+	565: true, 606: true, 612: true, 624: true, 627: true, 631: true,
+	632: true, 654: true, 662: true, 664: true, 703: true, 712: true,
+	723: true, 731: true, 732: true, 734: true, 743: true, 754: true,
+}
+
+// Validate returns an error if the frequency can't be rendered or the
+// tone, CTCSS, or DCS aren't standard, or the offset is out of range.
+func (f *Freq) Validate() error {
+	if f.Mhz <= 0 || f.Mhz >= 1000 {
+		return ErrFreqMhz
+	}
+
+	n := 0
+	if f.Tone > 0 {
+		n++
+		if !ctcssTones[f.Tone] {
+			return ErrFreqTone
+		}
+	}
+	if f.CTCSS > 0 {
+		n++
+		if !ctcssTones[f.CTCSS] {
+			return ErrFreqTone
+		}
+	}
+	if f.DCS > 0 {
+		n++
+		if !dcsCodes[f.DCS] {
+			return ErrFreqDCS
+		}
+	}
+	if n > 1 {
+		return ErrFreqSquelch
+	}
+
+	if f.Offset < -9999 || f.Offset > 9999 {
+		return ErrFreqOffset
+	}
+
+	if f.Range < 0 || f.Range > 999 {
+		return ErrFreqRange
+	}
+
+	return nil
+}
+
+// StandardOffset returns the standard repeater offset for the band the
+// frequency is in using the US band plan.  It's in 10 khz steps, the
+// same as Freq.Offset, so -60 is -600 khz.  It returns 0 for frequencies
+// without a standard offset.
+func StandardOffset(mhz float64) int {
+	switch {
+	case mhz >= 29.5 && mhz < 29.7:
+		return -10
+	case mhz >= 51 && mhz < 54:
+		return -50
+	case mhz >= 144 && mhz < 147:
+		return -60
+	case mhz >= 147 && mhz < 148:
+		return 60
+	case mhz >= 222 && mhz < 225:
+		return -160
+	case mhz >= 440 && mhz < 445:
+		return 500
+	case mhz >= 445 && mhz < 450:
+		return -500
+	case mhz >= 902 && mhz < 928:
+		return -1200
+	}
+
+	return 0
+}
+
+// SetStandardOffset sets the offset to the standard repeater offset for
+// the band if one isn't already set.  It returns false if there is no
+// standard offset for the band.  Offsets are never filled in unless
+// this is called.
+func (f *Freq) SetStandardOffset() bool {
+	if f.Offset != 0 {
+		return true
+	}
+	f.Offset = StandardOffset(f.Mhz)
+
+	return f.Offset != 0
+}
@@ -1,6 +1,7 @@
 package aprs
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -41,6 +42,39 @@ func TestRenderFreq(t *testing.T) {
 			},
 			want: "440.050MHz C100 -500 R025m ",
 		},
+		{
+			name: `146.94-repeater`,
+			pr: &PositionReport{
+				Freq: &Freq{
+					Mhz:    146.94,
+					Tone:   88,
+					Offset: -60,
+				},
+			},
+			want: "146.940MHz T088 -060 ",
+		},
+		{
+			name: `147.03-repeater`,
+			pr: &PositionReport{
+				Freq: &Freq{
+					Mhz:    147.03,
+					Tone:   100,
+					Offset: 60,
+				},
+			},
+			want: "147.030MHz T100 +060 ",
+		},
+		{
+			name: `conflicting-squelch`,
+			pr: &PositionReport{
+				Freq: &Freq{
+					Mhz:   440.050,
+					CTCSS: 100,
+					DCS:   23,
+				},
+			},
+			want: "440.050MHz C100 ",
+		},
 	}
 
 	for _, tc := range tests {
@@ -51,3 +85,173 @@ func TestRenderFreq(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFreq(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want *Freq
+		rest string
+	}{
+		{
+			name: `not_a_freq`,
+			s:    "Net Mondays 8pm",
+			want: nil,
+			rest: "Net Mondays 8pm",
+		},
+		{
+			name: `simple_146.72`,
+			s:    "146.720MHz ",
+			want: &Freq{Mhz: 146.72},
+		},
+		{
+			name: `freqspec-range`,
+			s:    "146.720MHz T100 R25m Net 8pm",
+			want: &Freq{Mhz: 146.72, Tone: 100, Range: 25},
+			rest: "Net 8pm",
+		},
+		{
+			name: `repeater-object`,
+			s:    "146.940MHz t088 -060 W1AW rptr",
+			want: &Freq{Mhz: 146.94, Tone: 88, Offset: -60, Narrow: true},
+			rest: "W1AW rptr",
+		},
+		{
+			name: `tone-off-km-range`,
+			s:    "444.000MHz Toff +500 R40k",
+			want: &Freq{Mhz: 444.0, Offset: 500, Range: 25},
+		},
+		{
+			name: `unsigned-offset`,
+			s:    "147.030MHz T100 060 R25m",
+			want: &Freq{Mhz: 147.03, Tone: 100, Offset: 60, Range: 25},
+		},
+		{
+			name: `dcs`,
+			s:    "440.050MHz D023 C100",
+			want: &Freq{Mhz: 440.05, DCS: 23, CTCSS: 100},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, rest := ParseFreq(tc.s)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Wanted: `%+v`. Got: `%+v`", tc.want, got)
+			}
+			if rest != tc.rest {
+				t.Fatalf("Wanted rest: `%s`. Got: `%s`", tc.rest, rest)
+			}
+		})
+	}
+}
+
+func TestParseFreqPosition(t *testing.T) {
+	want := PositionReport{
+		Lat:    35.7,
+		Lon:    -78.7,
+		Symbol: "/r",
+		Extn:   "PHG5132",
+		Freq: &Freq{
+			Mhz:    440.05,
+			CTCSS:  100,
+			Offset: -500,
+			Range:  25,
+		},
+		Comment: "Raleigh rptr",
+	}
+
+	got := PositionReport{}
+	if err := got.FromString(want.String()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Freq, want.Freq) {
+		t.Fatalf("Wanted: `%+v`. Got: `%+v`", want.Freq, got.Freq)
+	}
+	if got.Comment != want.Comment {
+		t.Fatalf("Wanted comment: `%s`. Got: `%s`", want.Comment, got.Comment)
+	}
+}
+
+func TestFreqRoundTrip(t *testing.T) {
+	for _, want := range []Freq{
+		{Mhz: 146.94, Tone: 88, Offset: -60},
+		{Mhz: 147.03, Tone: 100, Offset: 60, Range: 25},
+		{Mhz: 442.15, CTCSS: 100, Offset: 500, Narrow: true},
+		{Mhz: 440.05, DCS: 23},
+	} {
+		got, rest := ParseFreq(want.Render())
+		if got == nil || *got != want {
+			t.Errorf("Wanted: `%+v`. Got: `%+v`", want, got)
+		}
+		if rest != "" {
+			t.Errorf("Wanted no rest. Got: `%s`", rest)
+		}
+	}
+}
+
+func TestFreqValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Freq
+		want error
+	}{
+		{name: `simplex`, f: Freq{Mhz: 146.52}},
+		{name: `repeater`, f: Freq{Mhz: 146.94, Tone: 88, Offset: -60, Range: 25}},
+		{name: `dcs`, f: Freq{Mhz: 440.05, DCS: 23, Offset: 500}},
+		{name: `no_mhz`, f: Freq{}, want: ErrFreqMhz},
+		{name: `too_high`, f: Freq{Mhz: 1296.0}, want: ErrFreqMhz},
+		{name: `bad_tone`, f: Freq{Mhz: 146.94, Tone: 89}, want: ErrFreqTone},
+		{name: `bad_ctcss`, f: Freq{Mhz: 146.94, CTCSS: 300}, want: ErrFreqTone},
+		{name: `bad_dcs`, f: Freq{Mhz: 146.94, DCS: 24}, want: ErrFreqDCS},
+		{name: `tone_and_dcs`, f: Freq{Mhz: 146.94, Tone: 100, DCS: 23}, want: ErrFreqSquelch},
+		{name: `bad_offset`, f: Freq{Mhz: 146.94, Offset: 10000}, want: ErrFreqOffset},
+		{name: `bad_range`, f: Freq{Mhz: 146.94, Range: 1000}, want: ErrFreqRange},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.f.Validate(); got != tc.want {
+				t.Fatalf("Wanted: `%v`. Got: `%v`", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStandardOffset(t *testing.T) {
+	tests := []struct {
+		mhz  float64
+		want int
+	}{
+		{29.62, -10},
+		{53.35, -50},
+		{145.37, -60},
+		{146.94, -60},
+		{147.03, 60},
+		{224.5, -160},
+		{442.15, 500},
+		{447.5, -500},
+		{927.5, -1200},
+		{7.2, 0},
+	}
+
+	for _, tc := range tests {
+		if got := StandardOffset(tc.mhz); got != tc.want {
+			t.Errorf("%.3f wanted: `%d`. Got: `%d`", tc.mhz, tc.want, got)
+		}
+	}
+
+	f := Freq{Mhz: 147.03}
+	if !f.SetStandardOffset() || f.Offset != 60 {
+		t.Errorf("Wanted standard offset 60. Got: `%d`", f.Offset)
+	}
+	f = Freq{Mhz: 146.94}
+	f.Offset = StandardOffset(f.Mhz)
+	if want := "146.940MHz -060 "; f.Render() != want {
+		t.Errorf("Wanted: `%s`. Got: `%s`", want, f.Render())
+	}
+	f = Freq{Mhz: 7.2}
+	if f.SetStandardOffset() {
+		t.Errorf("Wanted no standard offset. Got: `%d`", f.Offset)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGPSStale is the default maximum age of the RMC and GGA values
+// in a GPS position report.
+const DefaultGPSStale = 10 * time.Second
+
+// GPSFix is a GPS receiver's current fix.  Speed and course are from the
+// last RMC sentence and altitude, quality, and satellites are from the
+// last GGA sentence, so they may be older than the position.
+type GPSFix struct {
+	Time     time.Time // UTC
+	Valid    bool
+	Lat      float64
+	Lon      float64
+	Speed    float64 // knots
+	Course   float64 // degrees; -1 if unknown
+	Altitude float64 // meters above mean sea level
+	Quality  int     // GGA fix quality
+	Sats     int     // satellites in use
+
+	RMCTime time.Time // time of the last RMC sentence; zero if none
+	GGATime time.Time // time of the last GGA sentence; zero if none or it arrived before an RMC supplied the date
+}
+
+// GPS maintains the current fix from a stream of NMEA sentences, such as
+// from a serial GPS receiver.  RMC sentences provide the date, speed, and
+// course, and GGA sentences provide the altitude and fix quality.
+type GPS struct {
+	Stale time.Duration // maximum age of RMC and GGA values relative to the fix; defaults to DefaultGPSStale
+
+	mu  sync.Mutex
+	fix GPSFix
+}
+
+// Read updates the fix from the NMEA sentences, one per line, in r until
+// it returns an error or EOF.  Invalid sentences, such as those with a bad
+// checksum, are ignored.
+func (g *GPS) Read(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		n := NMEA{}
+		if err := n.FromString(strings.TrimSpace(s.Text())); err != nil {
+			continue
+		}
+		g.Update(n)
+	}
+
+	return s.Err()
+}
+
+// Update updates the fix from a decoded NMEA sentence.  It returns false
+// if the sentence type doesn't carry a fix.
+func (g *GPS) Update(n NMEA) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(n.Type, "RMC"):
+		g.fix.Time, g.fix.RMCTime = n.Time, n.Time
+		g.fix.Speed, g.fix.Course = n.Speed, n.Course
+	case strings.HasSuffix(n.Type, "GGA"):
+		g.fix.Time = g.timeOfDay(n.Time)
+		// A GGA is only dated once an RMC has supplied the date.
+		g.fix.GGATime = time.Time{}
+		if !g.fix.RMCTime.IsZero() {
+			g.fix.GGATime = g.fix.Time
+		}
+		g.fix.Altitude, g.fix.Quality, g.fix.Sats = n.Altitude, n.Quality, n.Sats
+	case strings.HasSuffix(n.Type, "GLL"):
+		g.fix.Time = g.timeOfDay(n.Time)
+	default:
+		return false
+	}
+
+	g.fix.Valid = n.Valid
+	if n.Valid {
+		g.fix.Lat, g.fix.Lon = n.Lat, n.Lon
+	}
+
+	return true
+}
+
+// timeOfDay returns the time of day from a sentence without a date on the
+// date of the current fix, rolling over to the next day at midnight.
+func (g *GPS) timeOfDay(t time.Time) time.Time {
+	if g.fix.Time.IsZero() || t.IsZero() {
+		return t
+	}
+
+	last := g.fix.Time
+	tod := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	t = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC).Add(tod)
+	if last.Sub(t) > 12*time.Hour {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t
+}
+
+// Fix returns the current fix.
+func (g *GPS) Fix() GPSFix {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.fix
+}
+
+// PositionReport returns a position report for the current fix with the
+// timestamp, course/speed data extension, and altitude filled in.  The
+// course/speed and altitude are left out if their RMC or GGA sentence is
+// more than Stale from the fix time.  The symbol and comment are left for the caller to
+// set.  It returns false if there isn't a valid fix.
+func (g *GPS) PositionReport() (p PositionReport, ok bool) {
+	fix := g.Fix()
+	if !fix.Valid {
+		return
+	}
+
+	fresh := func(t time.Time) bool {
+		d := fix.Time.Sub(t)
+		return !t.IsZero() && d <= g.stale() && -d <= g.stale()
+	}
+
+	p.Timestamp = fix.Time
+	p.Lat, p.Lon = fix.Lat, fix.Lon
+	if fresh(fix.RMCTime) {
+		p.CSExtension(aprsCourse(fix.Course), int(math.Round(fix.Speed)), 0, 0)
+	}
+	if fresh(fix.GGATime) {
+		p.Altitude = int(math.Round(fix.Altitude * 3.28084))
+	}
+
+	return p, true
+}
+
+func (g *GPS) stale() time.Duration {
+	if g.Stale > 0 {
+		return g.Stale
+	}
+	return DefaultGPSStale
+}
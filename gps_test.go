@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGPSRead(t *testing.T) {
+	a := assert.New(t)
+
+	f, err := os.Open("testdata/gps.nmea")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g := GPS{}
+	a.Nil(g.Read(f), "Read log")
+
+	fix := g.Fix()
+	a.True(fix.Valid, "Valid fix")
+	a.Equal(time.Date(2016, time.November, 5, 0, 0, 2, 0, time.UTC), fix.Time, "Time")
+	a.InDelta(35.700167, fix.Lat, 0.000001, "Latitude")
+	a.InDelta(-78.699667, fix.Lon, 0.000001, "Longitude")
+	a.Equal(24.6, fix.Speed, "Speed")
+	a.Equal(89.6, fix.Course, "Course")
+	a.Equal(106.7, fix.Altitude, "Altitude")
+	a.Equal(2, fix.Quality, "Quality")
+	a.Equal(9, fix.Sats, "Satellites")
+
+	p, ok := g.PositionReport()
+	a.True(ok, "Position report")
+	a.Equal(fix.Time, p.Timestamp, "Timestamp")
+	a.Equal("090/025", p.Extn, "Course/speed")
+	a.Equal(350, p.Altitude, "Altitude in feet")
+	a.Equal("/050000z3542.01N/07841.98W/090/025/A=000350", p.String(), "Rendered")
+}
+
+func TestGPSStale(t *testing.T) {
+	a := assert.New(t)
+
+	g := GPS{}
+	a.Nil(g.Read(strings.NewReader(strings.Join([]string{
+		"$GPRMC,120000,A,3542.0000,N,07842.0000,W,12.0,0.0,051116,,",
+		"$GPGGA,120005,3542.0100,N,07841.9800,W,1,07,1.1,95.0,M,-33.0,M,,",
+	}, "\n"))), "Read")
+	p, ok := g.PositionReport()
+	a.True(ok, "Position report")
+	a.Equal("360/012", p.Extn, "North course/speed")
+	a.Equal(312, p.Altitude, "Altitude in feet")
+
+	a.Nil(g.Read(strings.NewReader("$GPGGA,120015,3542.0200,N,07841.9700,W,1,07,1.1,96.0,M,-33.0,M,,")), "Read")
+	p, _ = g.PositionReport()
+	a.Equal("", p.Extn, "Stale course/speed")
+	a.Equal(315, p.Altitude, "Altitude in feet")
+
+	g = GPS{}
+	a.Nil(g.Read(strings.NewReader("$GPGGA,120000,3542.0000,N,07842.0000,W,1,07,1.1,95.0,M,-33.0,M,,")), "Read")
+	p, _ = g.PositionReport()
+	a.Equal("", p.Extn, "No course/speed without RMC")
+
+	// A GGA before the first RMC can't be dated, such as when replaying
+	// an old log.
+	g = GPS{}
+	a.Nil(g.Read(strings.NewReader(strings.Join([]string{
+		"$GPGGA,120000,3542.0000,N,07842.0000,W,1,07,1.1,95.0,M,-33.0,M,,",
+		"$GPRMC,120001,A,3542.0000,N,07842.0000,W,12.0,90.0,051116,,",
+	}, "\n"))), "Read")
+	p, _ = g.PositionReport()
+	a.Equal("090/012", p.Extn, "Course/speed")
+	a.Equal(0, p.Altitude, "Undated altitude")
+}
+
+func TestGPSNoFix(t *testing.T) {
+	a := assert.New(t)
+
+	g := GPS{}
+	_, ok := g.PositionReport()
+	a.False(ok, "No sentences")
+
+	a.Nil(g.Read(strings.NewReader("$GPRMC,235955,V,,,,,,,041116,,*3F\n")), "Read")
+	_, ok = g.PositionReport()
+	a.False(ok, "Invalid fix")
+	a.Equal(time.Date(2016, time.November, 4, 23, 59, 55, 0, time.UTC), g.Fix().Time, "Time without a fix")
+
+	n := NMEA{}
+	a.Nil(n.FromString("$PGRMZ,246,f,3*1B"), "Valid sentence")
+	a.False(g.Update(n), "Sentence without a fix")
+}
+
+func TestGPSMidnight(t *testing.T) {
+	a := assert.New(t)
+
+	g := GPS{}
+	a.Nil(g.Read(strings.NewReader(strings.Join([]string{
+		"$GPRMC,235958,A,3542.0000,N,07842.0000,W,0.0,0.0,041116,,*05",
+		"$GPGGA,000002,3542.0100,N,07841.9800,W,2,09,0.9,106.7,M,-33.0,M,,*76",
+	}, "\n"))), "Read")
+	a.Equal(time.Date(2016, time.November, 5, 0, 0, 2, 0, time.UTC), g.Fix().Time, "Time after midnight")
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"math"
+	"strings"
+)
+
+// maidenheadPair is a Maidenhead locator character pair: the first
+// character, the number of divisions, and the size in degrees of
+// longitude.  Latitude sizes are half as large.
+type maidenheadPair struct {
+	base  byte
+	n     int
+	width float64
+}
+
+// maidenheadPairs are the locator pairs from largest to smallest.
+var maidenheadPairs = [5]maidenheadPair{
+	{'A', 18, 20.0},         // field
+	{'0', 10, 2.0},          // square
+	{'A', 24, 2.0 / 24.0},   // subsquare
+	{'0', 10, 2.0 / 240.0},  // extended square
+	{'A', 24, 2.0 / 5760.0}, // extended subsquare
+}
+
+// Maidenhead returns the n character, 4, 6, 8, or 10, Maidenhead
+// locator for the latitude and longitude.
+func Maidenhead(lat, lon float64, n int) (string, error) {
+	if n < 4 || n > 10 || n%2 != 0 || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return "", ErrLocatorInvalid
+	}
+
+	// The north pole and antimeridian belong to the last square.
+	x := math.Min(lon+180.0, 360.0-1e-9)
+	y := math.Min(lat+90.0, 180.0-1e-9)
+
+	loc := make([]byte, n)
+	for i := 0; i < n/2; i++ {
+		p := maidenheadPairs[i]
+		lonIdx := int(x / p.width)
+		latIdx := int(y / (p.width / 2.0))
+		loc[i*2] = p.base + byte(min(p.n-1, lonIdx))
+		loc[i*2+1] = p.base + byte(min(p.n-1, latIdx))
+		x -= float64(lonIdx) * p.width
+		y -= float64(latIdx) * p.width / 2.0
+	}
+
+	return string(loc), nil
+}
+
+// ParseMaidenhead returns the latitude and longitude of the center of a
+// 2, 4, 6, 8, or 10 character Maidenhead locator.  Letters are case
+// insensitive.
+func ParseMaidenhead(loc string) (lat, lon float64, err error) {
+	if len(loc) < 2 || len(loc) > 10 || len(loc)%2 != 0 {
+		return 0, 0, ErrLocatorInvalid
+	}
+	loc = strings.ToUpper(loc)
+
+	var p maidenheadPair
+	for i := 0; i < len(loc)/2; i++ {
+		p = maidenheadPairs[i]
+		lonIdx, latIdx := int(loc[i*2])-int(p.base), int(loc[i*2+1])-int(p.base)
+		if lonIdx < 0 || lonIdx >= p.n || latIdx < 0 || latIdx >= p.n {
+			return 0, 0, ErrLocatorInvalid
+		}
+		lon += float64(lonIdx) * p.width
+		lat += float64(latIdx) * p.width / 2.0
+	}
+
+	// Center of the smallest pair.
+	lon += p.width/2.0 - 180.0
+	lat += p.width/4.0 - 90.0
+
+	return
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMaidenhead() {
+	for _, n := range []int{4, 6, 8, 10} {
+		loc, _ := Maidenhead(51.4921, -0.2102, n)
+		fmt.Println(loc)
+	}
+
+	// Output:
+	// IO91
+	// IO91VL
+	// IO91VL48
+	// IO91VL48SC
+}
+
+func TestMaidenhead(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		lat, lon float64
+		loc      string
+	}{
+		{35.7, -78.7, "FM05PQ"},
+		{-33.8688, 151.2093, "QF56OD"},
+		{90, 180, "RR99XX"},
+		{-90, -180, "AA00AA"},
+		{0, 0, "JJ00AA"},
+	}
+
+	for _, test := range tests {
+		loc, err := Maidenhead(test.lat, test.lon, 6)
+		a.Nil(err, "Valid position %f,%f", test.lat, test.lon)
+		a.Equal(test.loc, loc, "Locator %f,%f", test.lat, test.lon)
+	}
+
+	for _, n := range []int{0, 2, 5, 12} {
+		_, err := Maidenhead(0, 0, n)
+		a.Equal(ErrLocatorInvalid, err, "Invalid length %d", n)
+	}
+	_, err := Maidenhead(91, 0, 6)
+	a.Equal(ErrLocatorInvalid, err, "Invalid latitude")
+}
+
+func TestParseMaidenhead(t *testing.T) {
+	a := assert.New(t)
+
+	lat, lon, err := ParseMaidenhead("IO91")
+	a.Nil(err, "Valid locator")
+	a.Equal(51.5, lat, "Square latitude")
+	a.Equal(-1.0, lon, "Square longitude")
+
+	lat, lon, err = ParseMaidenhead("fm05qq")
+	a.Nil(err, "Valid lowercase locator")
+	a.InDelta(35.6875, lat, 0.0001, "Subsquare latitude")
+	a.InDelta(-78.625, lon, 0.0001, "Subsquare longitude")
+
+	// Round trip through the center of each precision.
+	for _, n := range []int{4, 6, 8, 10} {
+		loc, _ := Maidenhead(-33.8688, 151.2093, n)
+		lat, lon, err := ParseMaidenhead(loc)
+		a.Nil(err, "Valid locator %s", loc)
+		got, _ := Maidenhead(lat, lon, n)
+		a.Equal(loc, got, "Round trip %s", loc)
+	}
+
+	for _, loc := range []string{"", "I", "IO9", "SO91", "IOA1", "IO91SY", "IO91SX7A", "IO91SX77RXAA"} {
+		_, _, err := ParseMaidenhead(loc)
+		a.Equal(ErrLocatorInvalid, err, "Invalid locator %s", loc)
+	}
+}
+
+func TestGridSquarePosition(t *testing.T) {
+	a := assert.New(t)
+
+	p := PositionReport{}
+	a.Nil(p.FromString("[IO91SX] 35 miles NNW of London"), "Valid grid square")
+	a.InDelta(51.979, p.Lat, 0.001, "Latitude")
+	a.InDelta(-0.458, p.Lon, 0.001, "Longitude")
+	a.Equal(" 35 miles NNW of London", p.Comment, "Comment")
+
+	a.Nil(p.FromString("[FM05]"), "Valid 4 character grid square")
+	a.Equal(35.5, p.Lat, "Latitude")
+	a.Equal(-79.0, p.Lon, "Longitude")
+
+	for _, s := range []string{"[IO91SX", "[IO9]", "[ZZ99ZZ]"} {
+		a.Equal(ErrPosInvalid, p.FromString(s), "Invalid grid square %s", s)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 14: Messages, Bulletins and Announcements
+//
+// And the reply-ack addendum:
+// http://www.aprs.org/aprs11/replyacks.txt
+
+// Message length limits.
+const (
+	MsgAddresseeLen = 9
+	MsgTextLen      = 67
+	MsgIDLen        = 5
+)
+
+// Message represents an APRS message, or an acknowledgement or
+// rejection of one.
+type Message struct {
+	Addressee  string // up to 9 byte addressee
+	Text       string // message text; empty for acks and rejects
+	ID         string // optional message ID (up to 5 bytes); the acked/rejected ID for acks and rejects
+	ReplyAck   bool   // message ID uses the reply-ack {MM}AA scheme
+	ReplyAckID string // message ID acknowledged by a reply-ack
+	Ack        bool   // message is an acknowledgement of ID
+	Rej        bool   // message is a rejection of ID
+}
+
+// String returns a rendered message suitable for sending to a TNC.
+func (m Message) String() string {
+	out := fmt.Sprintf(":%-9s:", m.Addressee)
+
+	switch {
+	case m.Ack:
+		out += "ack" + m.ID
+	case m.Rej:
+		out += "rej" + m.ID
+	default:
+		out += m.Text
+		if m.ID != "" {
+			out += "{" + m.ID
+		}
+	}
+
+	if m.ReplyAck {
+		out += "}" + m.ReplyAckID
+	}
+
+	return out
+}
+
+// Validate returns an error if the addressee, text, or message IDs
+// don't conform to the specification.
+func (m Message) Validate() error {
+	if len(m.Addressee) < 1 || len(m.Addressee) > MsgAddresseeLen || strings.ContainsRune(m.Addressee, ':') {
+		return ErrMsgAddressee
+	}
+
+	if len(m.Text) > MsgTextLen || strings.ContainsAny(m.Text, "|~{") {
+		return ErrMsgText
+	}
+
+	if (m.Ack || m.Rej || m.ReplyAck) && m.ID == "" {
+		return ErrMsgID
+	}
+	if m.ID != "" && !validMsgID(m.ID) {
+		return ErrMsgID
+	}
+	if m.ReplyAckID != "" && !validMsgID(m.ReplyAckID) {
+		return ErrMsgID
+	}
+
+	return nil
+}
+
+// validMsgID returns true if the ID is 1-5 alphanumeric bytes.
+func validMsgID(id string) bool {
+	if len(id) < 1 || len(id) > MsgIDLen {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FromString sets the message from a rendered message.
+func (m *Message) FromString(s string) error {
+	*m = Message{}
+
+	// :AAAAAAAAA:text{id
+	if len(s) < 11 || s[0] != ':' || s[10] != ':' {
+		return ErrMsgInvalid
+	}
+	m.Addressee = strings.TrimRight(s[1:10], " ")
+	text := s[11:]
+
+	// An ack or rej is only recognized when followed by a valid
+	// message ID, otherwise it's ordinary text.
+	if len(text) > 3 && !strings.ContainsRune(text, '{') {
+		switch text[:3] {
+		case "ack", "rej":
+			id, replyAckID, replyAck := splitMsgID(text[3:])
+			if validMsgID(id) {
+				m.Ack, m.Rej = text[:3] == "ack", text[:3] == "rej"
+				m.ID, m.ReplyAckID, m.ReplyAck = id, replyAckID, replyAck
+				return nil
+			}
+		}
+	}
+
+	if i := strings.LastIndexByte(text, '{'); i >= 0 {
+		m.Text = text[:i]
+		m.ID, m.ReplyAckID, m.ReplyAck = splitMsgID(text[i+1:])
+	} else {
+		m.Text = text
+	}
+
+	return nil
+}
+
+// splitMsgID splits a message ID into the ID and, if it uses the
+// reply-ack scheme, the acknowledged ID.
+func splitMsgID(s string) (id, replyAckID string, replyAck bool) {
+	s = strings.TrimSpace(s)
+	id, replyAckID, replyAck = strings.Cut(s, "}")
+
+	return
+}
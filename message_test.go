@@ -0,0 +1,75 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMessage_String() {
+	fmt.Println(Message{Addressee: "WU2Z", Text: "Testing", ID: "003"})
+	fmt.Println(Message{Addressee: "KB2ICI-14", ID: "003", Ack: true})
+	fmt.Println(Message{Addressee: "KB2ICI-14", ID: "003", Rej: true})
+	fmt.Println(Message{Addressee: "N0CALL", Text: "Hi", ID: "MM", ReplyAck: true})
+	fmt.Println(Message{Addressee: "N0CALL", Text: "Hi back", ID: "NN", ReplyAck: true, ReplyAckID: "MM"})
+
+	// Output:
+	// :WU2Z     :Testing{003
+	// :KB2ICI-14:ack003
+	// :KB2ICI-14:rej003
+	// :N0CALL   :Hi{MM}
+	// :N0CALL   :Hi back{NN}MM
+}
+
+func TestMessageFromString(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want Message
+	}{
+		{":WU2Z     :Testing", Message{Addressee: "WU2Z", Text: "Testing"}},
+		{":WU2Z     :Testing{003", Message{Addressee: "WU2Z", Text: "Testing", ID: "003"}},
+		{":KB2ICI-14:ack003", Message{Addressee: "KB2ICI-14", ID: "003", Ack: true}},
+		{":KB2ICI-14:rej003 ", Message{Addressee: "KB2ICI-14", ID: "003", Rej: true}},
+		{":N0CALL   :Hi{MM}", Message{Addressee: "N0CALL", Text: "Hi", ID: "MM", ReplyAck: true}},
+		{":N0CALL   :Hi back{NN}MM", Message{Addressee: "N0CALL", Text: "Hi back", ID: "NN", ReplyAck: true, ReplyAckID: "MM"}},
+		{":N0CALL   :ackMM}", Message{Addressee: "N0CALL", ID: "MM", Ack: true, ReplyAck: true}},
+		{":N0CALL   :acknowledged, thanks", Message{Addressee: "N0CALL", Text: "acknowledged, thanks"}},
+		{":BLN1     :Net tonight at 8pm", Message{Addressee: "BLN1", Text: "Net tonight at 8pm"}},
+	} {
+		m := Message{}
+		assert.Nil(t, m.FromString(test.s), "Valid message %s", test.s)
+		assert.Equal(t, test.want, m, "Message %s", test.s)
+		assert.Equal(t, strings.TrimSpace(test.s), m.String(), "Round trip %s", test.s)
+	}
+
+	for _, s := range []string{"", ":WU2Z:Testing", "WU2Z     :Testing"} {
+		m := Message{}
+		assert.Equal(t, ErrMsgInvalid, m.FromString(s), "Invalid message %s", s)
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	for _, test := range []struct {
+		m    Message
+		want error
+	}{
+		{Message{Addressee: "WU2Z", Text: "Testing", ID: "003"}, nil},
+		{Message{Addressee: "KB2ICI-14", ID: "003", Ack: true}, nil},
+		{Message{Addressee: "", Text: "Testing"}, ErrMsgAddressee},
+		{Message{Addressee: "KB2ICI-14X", Text: "Testing"}, ErrMsgAddressee},
+		{Message{Addressee: "WU2Z", Text: strings.Repeat("x", 68)}, ErrMsgText},
+		{Message{Addressee: "WU2Z", Text: "a|b"}, ErrMsgText},
+		{Message{Addressee: "WU2Z", Text: "a{b"}, ErrMsgText},
+		{Message{Addressee: "WU2Z", Text: "Testing", ID: "123456"}, ErrMsgID},
+		{Message{Addressee: "WU2Z", Text: "Testing", ID: "1-2"}, ErrMsgID},
+		{Message{Addressee: "WU2Z", Ack: true}, ErrMsgID},
+	} {
+		assert.Equal(t, test.want, test.m.Validate(), "Validate %s", test.m)
+	}
+}
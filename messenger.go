@@ -0,0 +1,275 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Messenger defaults.
+const (
+	DefaultMsgRetry   = 30 * time.Second
+	DefaultMsgRetries = 5
+)
+
+// MsgStatus is the final delivery status of a message.
+type MsgStatus int
+
+// Message delivery statuses.
+const (
+	MsgDelivered MsgStatus = iota // acknowledged by the addressee
+	MsgRejected                   // rejected by the addressee
+	MsgTimedOut                   // retries exhausted without an ack or rej
+)
+
+// String returns the name of the status.
+func (s MsgStatus) String() string {
+	switch s {
+	case MsgDelivered:
+		return "delivered"
+	case MsgRejected:
+		return "rejected"
+	case MsgTimedOut:
+		return "timed out"
+	}
+
+	return "unknown"
+}
+
+// MsgResult is the outcome of sending a message.
+type MsgResult struct {
+	Msg    Message
+	Status MsgStatus
+	Sent   int   // number of transmissions
+	Err    error // error from the last transmission, if it failed
+}
+
+// Messenger is a reliable messaging session.  It assigns message IDs
+// per addressee and retransmits each message on a decaying schedule,
+// doubling the interval each time, until an ack or rej is received or
+// the retries are exhausted.
+//
+// Inbound frames must be passed to Handle, or Run, so acks can be
+// matched.  Retransmissions happen on calls to Tick, which Run calls
+// every second.
+type Messenger struct {
+	Src    Addr   // our address; messages addressed here are matched
+	Dst    Addr   // destination address; defaults to APRS
+	Path   Path   // digipath
+	Sender Sender // transport
+
+	Retry   time.Duration    // initial retry interval; defaults to DefaultMsgRetry
+	Retries int              // maximum number of retransmissions; defaults to DefaultMsgRetries
+	Now     func() time.Time // clock; defaults to time.Now
+
+	mu      sync.Mutex
+	ids     map[string]int
+	pending []*pendingMsg
+}
+
+// pendingMsg is a message awaiting an ack or rej.
+type pendingMsg struct {
+	msg      Message
+	sent     int
+	interval time.Duration
+	next     time.Time
+	err      error
+	result   chan MsgResult
+}
+
+// Send validates and transmits a message to the addressee.  The
+// returned channel receives the result once the message is delivered,
+// rejected, or timed out.
+func (m *Messenger) Send(addressee, text string) (<-chan MsgResult, error) {
+	msg := Message{Addressee: addressee, Text: text}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	msg.ID = m.nextID(addressee)
+	p := &pendingMsg{
+		msg:      msg,
+		interval: m.retry(),
+		result:   make(chan MsgResult, 1),
+	}
+	m.pending = append(m.pending, p)
+	f := m.schedule(p, m.now())
+	m.mu.Unlock()
+
+	m.transmit(p, f)
+
+	return p.result, nil
+}
+
+// Handle matches an inbound frame against pending messages.  It
+// returns true if the frame was an ack or rej for one of them.
+func (m *Messenger) Handle(f Frame) bool {
+	if len(f.Text) < 1 || f.Text[0] != ':' {
+		return false
+	}
+	msg := Message{}
+	if err := msg.FromString(f.Text); err != nil {
+		return false
+	}
+	if !strings.EqualFold(msg.Addressee, m.Src.String()) {
+		return false
+	}
+
+	status := MsgDelivered
+	var id string
+	switch {
+	case msg.Ack:
+		id = msg.ID
+	case msg.Rej:
+		status, id = MsgRejected, msg.ID
+	case msg.ReplyAckID != "":
+		id = msg.ReplyAckID
+	default:
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from := Addr{Call: f.Src.Call, SSID: f.Src.SSID}.String()
+	for i, p := range m.pending {
+		if p.msg.ID == id && strings.EqualFold(p.msg.Addressee, from) {
+			m.finish(i, status)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tick retransmits messages that are due and times out messages whose
+// retries are exhausted.
+func (m *Messenger) Tick() {
+	m.mu.Lock()
+	var due []*pendingMsg
+	var frames []Frame
+	now := m.now()
+	for i := 0; i < len(m.pending); i++ {
+		p := m.pending[i]
+		if now.Before(p.next) {
+			continue
+		}
+		if p.sent > m.retries() {
+			m.finish(i, MsgTimedOut)
+			i--
+			continue
+		}
+		due = append(due, p)
+		frames = append(frames, m.schedule(p, now))
+	}
+	m.mu.Unlock()
+
+	for i, p := range due {
+		m.transmit(p, frames[i])
+	}
+}
+
+// Pending returns the number of messages awaiting an ack or rej.
+func (m *Messenger) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.pending)
+}
+
+// Run handles inbound frames and ticks every second until the context
+// is canceled or the frame channel is closed.
+func (m *Messenger) Run(ctx context.Context, fc <-chan Frame) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case f, ok := <-fc:
+			if !ok {
+				return
+			}
+			m.Handle(f)
+		case <-t.C:
+			m.Tick()
+		}
+	}
+}
+
+// nextID returns the next message ID for the addressee.
+func (m *Messenger) nextID(addressee string) string {
+	if m.ids == nil {
+		m.ids = map[string]int{}
+	}
+	k := strings.ToUpper(addressee)
+	m.ids[k] = m.ids[k]%99999 + 1
+
+	return strconv.Itoa(m.ids[k])
+}
+
+// schedule counts a transmission of the message, schedules the next
+// retransmission, and returns the frame to send.  The lock must be held.
+func (m *Messenger) schedule(p *pendingMsg, now time.Time) Frame {
+	if p.sent > 0 {
+		p.interval *= 2
+	}
+	p.sent++
+	p.next = now.Add(p.interval)
+
+	dst := m.Dst
+	if dst.Call == "" {
+		dst = Addr{Call: "APRS"}
+	}
+
+	return Frame{Dst: dst, Src: m.Src, Path: m.Path, Text: p.msg.String()}
+}
+
+// transmit sends the frame for the message and records the outcome,
+// so a successful retransmission clears an earlier error.  The lock
+// must not be held since sending may block on the network.
+func (m *Messenger) transmit(p *pendingMsg, f Frame) {
+	err := m.Sender.Send(f)
+
+	m.mu.Lock()
+	p.err = err
+	m.mu.Unlock()
+}
+
+// finish removes the pending message at index i and sends its result.
+func (m *Messenger) finish(i int, status MsgStatus) {
+	p := m.pending[i]
+	m.pending = append(m.pending[:i], m.pending[i+1:]...)
+
+	p.result <- MsgResult{Msg: p.msg, Status: status, Sent: p.sent, Err: p.err}
+	close(p.result)
+}
+
+func (m *Messenger) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
+
+func (m *Messenger) retry() time.Duration {
+	if m.Retry <= 0 {
+		return DefaultMsgRetry
+	}
+	return m.Retry
+}
+
+func (m *Messenger) retries() int {
+	if m.Retries <= 0 {
+		return DefaultMsgRetries
+	}
+	return m.Retries
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMessenger returns a Messenger with a fake clock and a sender
+// which records transmitted frames.
+func testMessenger() (m *Messenger, sent *[]Frame, now *time.Time) {
+	sent = &[]Frame{}
+	now = &time.Time{}
+	*now = time.Date(2016, time.November, 5, 20, 35, 0, 0, time.UTC)
+	m = &Messenger{
+		Src: Addr{Call: "N0CALL", SSID: 13},
+		Sender: SenderFunc(func(f Frame) error {
+			*sent = append(*sent, f)
+			return nil
+		}),
+		Retry:   10 * time.Second,
+		Retries: 3,
+		Now:     func() time.Time { return *now },
+	}
+
+	return
+}
+
+func ack(text string) Frame {
+	f := Frame{}
+	f.FromString("WU2Z>APRS::N0CALL-13:" + text)
+	return f
+}
+
+func TestMessengerDelivered(t *testing.T) {
+	a := assert.New(t)
+	m, sent, now := testMessenger()
+
+	rc, err := m.Send("WU2Z", "Testing")
+	a.Nil(err, "Send")
+	a.Len(*sent, 1, "Initial transmission")
+	a.Equal("N0CALL-13>APRS::WU2Z     :Testing{1", (*sent)[0].String(), "Frame")
+
+	// IDs are per addressee
+	_, err = m.Send("WU2Z", "Again")
+	a.Nil(err, "Send")
+	_, err = m.Send("KB2ICI", "Hello")
+	a.Nil(err, "Send")
+	a.Equal(":WU2Z     :Again{2", (*sent)[1].Text, "Second ID")
+	a.Equal(":KB2ICI   :Hello{1", (*sent)[2].Text, "Other addressee ID")
+
+	*now = now.Add(10 * time.Second)
+	m.Tick()
+	a.Len(*sent, 6, "Retransmissions")
+
+	a.False(m.Handle(ack("ack9")), "Unknown ID")
+	a.True(m.Handle(ack("ack1")), "Ack")
+	r := <-rc
+	a.Equal(MsgDelivered, r.Status, "Status")
+	a.Equal(2, r.Sent, "Sent")
+	a.Equal(2, m.Pending(), "Pending")
+
+	a.True(m.Handle(ack("Roger{5}2")), "Reply-ack")
+	a.Equal(1, m.Pending(), "Pending")
+}
+
+func TestMessengerRejected(t *testing.T) {
+	m, _, _ := testMessenger()
+
+	rc, _ := m.Send("WU2Z", "Testing")
+	assert.True(t, m.Handle(ack("rej1")), "Rej")
+	assert.Equal(t, MsgRejected, (<-rc).Status, "Status")
+}
+
+func TestMessengerTimedOut(t *testing.T) {
+	a := assert.New(t)
+	m, sent, now := testMessenger()
+
+	errSend := errors.New("send failed")
+	m.Sender = SenderFunc(func(f Frame) error {
+		*sent = append(*sent, f)
+		return errSend
+	})
+
+	rc, _ := m.Send("WU2Z", "Testing")
+	// Decaying schedule: 10s, 20s, 40s, then give up 80s later.
+	for _, d := range []time.Duration{9, 1, 19, 1, 39, 1, 79} {
+		*now = now.Add(d * time.Second)
+		m.Tick()
+	}
+	a.Len(*sent, 4, "Transmissions")
+	a.Equal(1, m.Pending(), "Pending")
+
+	*now = now.Add(time.Second)
+	m.Tick()
+	r := <-rc
+	a.Equal(MsgTimedOut, r.Status, "Status")
+	a.Equal(4, r.Sent, "Sent")
+	a.Equal(errSend, r.Err, "Err")
+	a.Equal(0, m.Pending(), "Pending")
+}
+
+func TestMessengerRetrySucceeded(t *testing.T) {
+	m, sent, now := testMessenger()
+
+	m.Sender = SenderFunc(func(f Frame) error {
+		*sent = append(*sent, f)
+		if len(*sent) == 1 {
+			return errors.New("send failed")
+		}
+		return nil
+	})
+
+	rc, _ := m.Send("WU2Z", "Testing")
+	*now = now.Add(10 * time.Second)
+	m.Tick()
+	m.Handle(ack("ack1"))
+	assert.Nil(t, (<-rc).Err, "Err cleared by retransmission")
+}
+
+func TestMessengerSendUnlocked(t *testing.T) {
+	m, _, now := testMessenger()
+
+	// The sender may block, so the messenger must remain usable while
+	// it's sending.
+	m.Sender = SenderFunc(func(f Frame) error {
+		m.Pending()
+		return nil
+	})
+
+	m.Send("WU2Z", "Testing")
+	*now = now.Add(10 * time.Second)
+	m.Tick()
+	assert.Equal(t, 1, m.Pending(), "Pending")
+}
+
+func TestMessengerInvalid(t *testing.T) {
+	m, sent, _ := testMessenger()
+
+	_, err := m.Send("WU2Z", "a|b")
+	assert.Equal(t, ErrMsgText, err, "Invalid text")
+	assert.Len(t, *sent, 0, "Transmissions")
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 10: Mic-E Data Format
+//
+// Mic-E packs the latitude, message bits, and longitude offset/hemisphere
+// into the 6 byte destination address callsign.  The information field
+// contains the longitude, speed, course, and symbol followed by optional
+// telemetry or altitude and a status text.
+
+// MicEMsg is a Mic-E message code.  The value is the 3 message bits
+// (A, B, and C) from the destination address.
+type MicEMsg int
+
+// Mic-E message codes.  Custom messages C0-C6 share the same values
+// as M0-M6 but are distinguished by MicE.Custom.
+const (
+	MicEEmergency MicEMsg = iota
+	MicEPriority
+	MicESpecial
+	MicECommitted
+	MicEReturning
+	MicEInService
+	MicEEnRoute
+	MicEOffDuty
+)
+
+// String returns the standard name of the message code.
+func (m MicEMsg) String() string {
+	switch m {
+	case MicEEmergency:
+		return "Emergency"
+	case MicEPriority:
+		return "Priority"
+	case MicESpecial:
+		return "Special"
+	case MicECommitted:
+		return "Committed"
+	case MicEReturning:
+		return "Returning"
+	case MicEInService:
+		return "In Service"
+	case MicEEnRoute:
+		return "En Route"
+	case MicEOffDuty:
+		return "Off Duty"
+	}
+
+	return fmt.Sprintf("Unknown(%d)", int(m))
+}
+
+// MicE represents a Mic-E position report.
+type MicE struct {
+	Lat       float64 // latitude
+	Lon       float64 // longitude
+	Speed     int     // speed in knots
+	Course    int     // course in degrees
+	Symbol    string  // 2 byte Map symbol; see Chapter 20 aprs101
+	Msg       MicEMsg // message code
+	Custom    bool    // message code is custom (C0-C6) rather than standard (M0-M6)
+	Old       bool    // GPS data is old rather than current
+	Ambiguity int     // position ambiguity level (0-4)
+	Altitude  int     // altitude in meters; zero if unknown
+	Telemetry []int   // 2 or 5 channels of 8-bit telemetry
+	Device    string  // device type (e.g. "Kenwood TM-D710"); see micEDevices
+	Comment   string  // free-form status text
+}
+
+// micEDevices are the known device type prefix and suffix codes.  More
+// specific codes are listed first.
+var micEDevices = []struct {
+	prefix, suffix, name string
+}{
+	{">", "=", "Kenwood TH-D72"},
+	{">", "^", "Kenwood TH-D74"},
+	{">", "&", "Kenwood TH-D75"},
+	{">", "", "Kenwood TH-D7A"},
+	{"]", "=", "Kenwood TM-D710"},
+	{"]", "", "Kenwood TM-D700"},
+	{"`", "_ ", "Yaesu VX-8"},
+	{"`", `_"`, "Yaesu FTM-350"},
+	{"`", "_#", "Yaesu VX-8G"},
+	{"`", "_$", "Yaesu FT1D"},
+	{"`", "_%", "Yaesu FTM-400DR"},
+	{"`", "_)", "Yaesu FTM-100D"},
+	{"`", "_(", "Yaesu FT2D"},
+	{"`", "_0", "Yaesu FT3D"},
+	{"`", "_1", "Yaesu FTM-300D"},
+	{"`", "_3", "Yaesu FT5D"},
+	{"'", "|3", "Byonics TinyTrack3"},
+	{"'", "|4", "Byonics TinyTrack4"},
+}
+
+// reMicETelemetry matches the 2 or 5 channel hex telemetry.
+var reMicETelemetry = regexp.MustCompile("^(?:'([0-9a-fA-F]{4})|`([0-9a-fA-F]{10}))")
+
+// reMicEAltitude matches the base-91 altitude.
+var reMicEAltitude = regexp.MustCompile(`^[!-{]{3}}`)
+
+// Dst returns the destination address, which encodes the latitude,
+// message code, and longitude offset and hemisphere.
+func (m MicE) Dst() Addr {
+	lat := hundredthsMinutes(m.Lat)
+	digits := []byte(fmt.Sprintf("%02d%04d", lat/6000, lat%6000))
+	for i := range min(4, max(0, m.Ambiguity)) {
+		digits[5-i] = ' '
+	}
+
+	lonDeg := hundredthsMinutes(m.Lon) / 6000
+	flags := [6]bool{
+		m.Msg&4 > 0,
+		m.Msg&2 > 0,
+		m.Msg&1 > 0,
+		m.Lat >= 0,
+		lonDeg < 10 || lonDeg >= 100,
+		m.Lon < 0,
+	}
+
+	call := make([]byte, 6)
+	for i, d := range digits {
+		switch {
+		case flags[i] && i < 3 && m.Custom:
+			if d == ' ' {
+				call[i] = 'K'
+			} else {
+				call[i] = d - '0' + 'A'
+			}
+		case flags[i]:
+			if d == ' ' {
+				call[i] = 'Z'
+			} else {
+				call[i] = d - '0' + 'P'
+			}
+		default:
+			if d == ' ' {
+				call[i] = 'L'
+			} else {
+				call[i] = d
+			}
+		}
+	}
+
+	return Addr{Call: string(call)}
+}
+
+// String returns the rendered information field.
+func (m MicE) String() string {
+	dt := byte('`')
+	if m.Old {
+		dt = '\''
+	}
+
+	lon := hundredthsMinutes(m.Lon)
+	d, mm, h := lon/6000, lon%6000/100, lon%100
+	switch {
+	case d < 10:
+		d += 90
+	case d >= 110:
+		d -= 100
+	case d >= 100:
+		d -= 20
+	}
+	if mm < 10 {
+		mm += 60
+	}
+
+	speed := min(799, max(0, m.Speed))
+	course := min(360, max(0, m.Course))
+	sp, dc, se := speed/10, speed%10*10+course/100, course%100
+	// Keep the bytes printable using the equivalent +800 knots and
+	// +400 degrees encodings.
+	if sp < 4 {
+		sp += 80
+	}
+	if dc < 4 {
+		dc += 4
+	}
+
+	sym := m.Symbol
+	if len(sym) < 2 {
+		sym = "//" // default primary table, dot
+	}
+
+	out := string([]byte{
+		dt,
+		byte(d + 28), byte(mm + 28), byte(h + 28),
+		byte(sp + 28), byte(dc + 28), byte(se + 28),
+		sym[1], sym[0],
+	})
+
+	// Device type prefix or telemetry, which are mutually exclusive
+	prefix, suffix := m.deviceCodes()
+	if prefix != "" {
+		out += prefix
+	} else if len(m.Telemetry) > 0 {
+		out += m.renderTelemetry()
+	}
+
+	if m.Altitude != 0 {
+		out += encBase91(max(0, m.Altitude+10000), 3) + "}"
+	}
+
+	return out + m.Comment + suffix
+}
+
+// deviceCodes returns the prefix and suffix codes for the device type.
+func (m MicE) deviceCodes() (prefix, suffix string) {
+	for _, d := range micEDevices {
+		if d.name == m.Device {
+			return d.prefix, d.suffix
+		}
+	}
+
+	return
+}
+
+// renderTelemetry returns the 2 or 5 channel hex telemetry.
+func (m MicE) renderTelemetry() string {
+	out := "'"
+	n := 2
+	if len(m.Telemetry) > 2 {
+		out = "`"
+		n = 5
+	}
+	for i := range n {
+		v := 0
+		if i < len(m.Telemetry) {
+			v = min(255, max(0, m.Telemetry[i]))
+		}
+		out += fmt.Sprintf("%02x", v)
+	}
+
+	return out
+}
+
+// FromFrame sets the Mic-E report from a Frame's destination address
+// and information field.
+func (m *MicE) FromFrame(f Frame) (err error) {
+	*m = MicE{}
+
+	lonOffset, lonWest, err := m.parseDst(f.Dst.Call)
+	if err != nil {
+		return
+	}
+
+	s := f.Text
+	if len(s) < 9 {
+		return ErrPosShort
+	}
+	switch s[0] {
+	case '`':
+	case '\'':
+		m.Old = true
+	default:
+		return ErrPosDataType
+	}
+
+	// Longitude
+	d := int(s[1]) - 28
+	if lonOffset {
+		d += 100
+	}
+	switch {
+	case d >= 180 && d <= 189:
+		d -= 80
+	case d >= 190 && d <= 199:
+		d -= 190
+	}
+	mm := int(s[2]) - 28
+	if mm >= 60 {
+		mm -= 60
+	}
+	h := int(s[3]) - 28
+	if d < 0 || d > 179 || mm < 0 || mm > 59 || h < 0 || h > 99 {
+		return ErrPosInvalid
+	}
+	m.Lon = float64(d) + (float64(mm)+float64(h)/100.0)/60.0
+	if lonWest {
+		m.Lon = -m.Lon
+	}
+	// Longitude ambiguity is implied by the latitude and the position
+	// is the center of the ambiguity area.
+	if m.Ambiguity > 0 {
+		m.Lat = ambiguousCenter(m.Lat, m.Ambiguity)
+		m.Lon = ambiguousCenter(m.Lon, m.Ambiguity)
+	}
+
+	// Speed and course
+	sp, dc, se := int(s[4])-28, int(s[5])-28, int(s[6])-28
+	if sp < 0 || dc < 0 || se < 0 {
+		return ErrPosInvalid
+	}
+	m.Speed = (sp*10 + dc/10) % 800
+	m.Course = (dc%10*100 + se) % 400
+
+	m.Symbol = string([]byte{s[8], s[7]})
+
+	m.parseComment(s[9:])
+
+	return
+}
+
+// parseDst sets the latitude and message code from the destination
+// address callsign and returns the longitude offset and hemisphere.
+func (m *MicE) parseDst(call string) (lonOffset, lonWest bool, err error) {
+	if len(call) != 6 {
+		err = ErrMicEDst
+		return
+	}
+
+	digits := make([]byte, 6)
+	var flags [6]bool
+	for i := range 6 {
+		c := call[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits[i] = c
+		case c == 'L':
+			digits[i] = ' '
+		case c >= 'A' && c <= 'K' && i < 3:
+			flags[i], m.Custom = true, true
+			digits[i] = c - 'A' + '0'
+			if c == 'K' {
+				digits[i] = ' '
+			}
+		case c >= 'P' && c <= 'Z':
+			flags[i] = true
+			digits[i] = c - 'P' + '0'
+			if c == 'Z' {
+				digits[i] = ' '
+			}
+		default:
+			err = ErrMicEDst
+			return
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if flags[i] {
+			m.Msg |= 1 << (2 - i)
+		}
+	}
+
+	// Ambiguity blanks digits right to left.
+	for i := 5; i >= 2 && digits[i] == ' '; i-- {
+		digits[i] = '0'
+		m.Ambiguity++
+	}
+	v, err := strconv.Atoi(string(digits))
+	if err != nil || v%10000 >= 6000 || v/10000 > 90 {
+		err = ErrMicEDst
+		return
+	}
+	m.Lat = float64(v/10000) + float64(v%10000)/6000.0
+	if !flags[3] {
+		m.Lat = -m.Lat
+	}
+
+	return flags[4], flags[5], nil
+}
+
+// parseComment sets the device type, telemetry, altitude, and comment
+// from the text following the symbol.
+func (m *MicE) parseComment(s string) {
+	for _, d := range micEDevices {
+		if strings.HasPrefix(s, d.prefix) && strings.HasSuffix(s[len(d.prefix):], d.suffix) {
+			m.Device = d.name
+			s = s[len(d.prefix) : len(s)-len(d.suffix)]
+			break
+		}
+	}
+
+	if m.Device == "" {
+		if t := reMicETelemetry.FindStringSubmatch(s); t != nil {
+			hex := t[1] + t[2]
+			for i := 0; i < len(hex); i += 2 {
+				v, _ := strconv.ParseUint(hex[i:i+2], 16, 8)
+				m.Telemetry = append(m.Telemetry, int(v))
+			}
+			s = s[len(t[0]):]
+		}
+	}
+
+	if alt := reMicEAltitude.FindString(s); alt != "" {
+		v, _ := decBase91(alt[:3])
+		m.Altitude = v - 10000
+		s = s[len(alt):]
+	}
+
+	m.Comment = s
+}
+
+// hundredthsMinutes returns the absolute value of a latitude or
+// longitude in hundredths of minutes.
+func hundredthsMinutes(l float64) int {
+	return int(math.Round(math.Abs(l) * 6000.0))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMicE_String() {
+	m := MicE{
+		Lat:    33.427333,
+		Lon:    -112.129,
+		Speed:  20,
+		Course: 251,
+		Symbol: "/j",
+		Msg:    MicEReturning,
+	}
+	fmt.Println(m.Dst())
+	fmt.Println(m)
+
+	// Output:
+	// S32UVT
+	// `(_fn"Oj/
+}
+
+func TestMicEFromFrame(t *testing.T) {
+	a := assert.New(t)
+
+	m := MicE{}
+	err := m.FromFrame(Frame{Dst: Addr{Call: "S32UVT"}, Text: "`(_fn\"Oj/]\"4T}Hello=", Src: Addr{Call: "N0CALL"}})
+	a.Nil(err, "Valid Mic-E")
+	a.InDelta(33.427333, m.Lat, 0.00001, "Lat")
+	a.InDelta(-112.129, m.Lon, 0.00001, "Lon")
+	a.Equal(20, m.Speed, "Speed")
+	a.Equal(251, m.Course, "Course")
+	a.Equal("/j", m.Symbol, "Symbol")
+	a.Equal(MicEReturning, m.Msg, "Msg")
+	a.False(m.Custom, "Custom")
+	a.Equal("Kenwood TM-D710", m.Device, "Device")
+	a.Equal(61, m.Altitude, "Altitude")
+	a.Equal("Hello", m.Comment, "Comment")
+
+	m = MicE{}
+	err = m.FromFrame(Frame{Dst: Addr{Call: "ABCZZZ"}, Text: "'(_fn\"Oj/'1a2b"})
+	a.Nil(err, "Valid Mic-E")
+	a.InDelta(1.416667, m.Lat, 0.00001, "Lat at the center of the ambiguity area")
+	a.Equal(MicEOffDuty, m.Msg, "Msg")
+	a.True(m.Custom, "Custom")
+	a.True(m.Old, "Old")
+	a.Equal(3, m.Ambiguity, "Ambiguity")
+	a.Equal([]int{0x1a, 0x2b}, m.Telemetry, "Telemetry")
+
+	for _, f := range []Frame{
+		{Dst: Addr{Call: "APRS"}, Text: "`(_fn\"Oj/"},
+		{Dst: Addr{Call: "S3MUVT"}, Text: "`(_fn\"Oj/"},
+		{Dst: Addr{Call: "S32UVT"}, Text: "`(_fn\"O"},
+		{Dst: Addr{Call: "S32UVT"}, Text: "!(_fn\"Oj/"},
+	} {
+		a.NotNil(m.FromFrame(f), "Invalid Mic-E %s", f)
+	}
+}
+
+func TestMicERoundTrip(t *testing.T) {
+	for _, want := range []MicE{
+		{Lat: 35.7, Lon: -78.7, Symbol: `\j`, Msg: MicEEnRoute, Speed: 55, Course: 90},
+		{Lat: -46.071795, Lon: 169.6652273, Symbol: "/>", Msg: MicEEmergency, Altitude: 250},
+		{Lat: 0.5, Lon: 5.25, Symbol: "/[", Msg: 3, Custom: true, Device: "Yaesu FTM-400DR", Comment: "QRV"},
+		{Lat: 51.5, Lon: 105.1, Symbol: "/k", Msg: MicEOffDuty, Speed: 3, Course: 2, Telemetry: []int{1, 2, 3, 4, 5}},
+		{Lat: 49.5, Lon: -72.5, Symbol: "/-", Msg: MicEInService, Old: true, Ambiguity: 4},
+	} {
+		f := Frame{Dst: want.Dst(), Text: want.String()}
+		got := MicE{}
+		if err := got.FromFrame(f); err != nil {
+			t.Fatalf("Error decoding %s: %s", f, err)
+		}
+		assert.InDelta(t, want.Lat, got.Lat, 0.0001, "Lat")
+		assert.InDelta(t, want.Lon, got.Lon, 0.0001, "Lon")
+		got.Lat, got.Lon = want.Lat, want.Lon
+		assert.Equal(t, want, got, "Mic-E %s", f)
+	}
+}
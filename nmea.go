@@ -0,0 +1,298 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 6: Raw GPS Data (NMEA)
+
+// NMEA represents a raw NMEA sentence sent as the information field.
+// Position, time, and motion are decoded from RMC, GGA, and GLL
+// sentences from any talker; other sentences only have their type set.
+type NMEA struct {
+	Sentence string    // complete sentence, including the leading $
+	Type     string    // talker and sentence type, e.g. GPRMC
+	Time     time.Time // fix time in UTC; the date is only sent in RMC
+	Valid    bool      // fix is valid
+	Lat      float64
+	Lon      float64
+	Speed    float64 // knots (RMC)
+	Course   float64 // degrees (RMC); -1 if unknown
+	Altitude float64 // meters above mean sea level (GGA)
+	Quality  int     // fix quality (GGA)
+	Sats     int     // satellites in use (GGA)
+}
+
+// String returns the sentence.
+func (n NMEA) String() string {
+	return n.Sentence
+}
+
+// FromString sets the NMEA fields from a sentence.  The checksum is
+// optional but it must be correct if it's present.
+func (n *NMEA) FromString(s string) (err error) {
+	*n = NMEA{Sentence: s}
+
+	fields, err := nmeaFields(s)
+	if err != nil {
+		return
+	}
+	n.Type = fields[0]
+
+	now := time.Now().UTC()
+	switch {
+	case strings.HasSuffix(n.Type, "RMC"):
+		// RMC,hhmmss.ss,A,llll.ll,a,yyyyy.yy,a,x.x,x.x,ddmmyy,x.x,a
+		if len(fields) < 10 {
+			return ErrNMEAInvalid
+		}
+		if n.Time, err = parseNMEATime(fields[1], fields[9], now); err != nil {
+			return
+		}
+		n.Valid = fields[2] == "A"
+		if n.Lat, n.Lon, err = parseNMEALatLon(fields[3:7]); err != nil {
+			return
+		}
+		n.Speed, _ = strconv.ParseFloat(fields[7], 64)
+		n.Course = -1.0
+		if fields[8] != "" {
+			n.Course, _ = strconv.ParseFloat(fields[8], 64)
+		}
+	case strings.HasSuffix(n.Type, "GGA"):
+		// GGA,hhmmss.ss,llll.ll,a,yyyyy.yy,a,x,xx,x.x,x.x,M,...
+		if len(fields) < 10 {
+			return ErrNMEAInvalid
+		}
+		if n.Time, err = parseNMEATime(fields[1], "", now); err != nil {
+			return
+		}
+		if n.Lat, n.Lon, err = parseNMEALatLon(fields[2:6]); err != nil {
+			return
+		}
+		n.Quality, _ = strconv.Atoi(fields[6])
+		n.Valid = n.Quality > 0
+		n.Sats, _ = strconv.Atoi(fields[7])
+		n.Altitude, _ = strconv.ParseFloat(fields[9], 64)
+	case strings.HasSuffix(n.Type, "GLL"):
+		// GLL,llll.ll,a,yyyyy.yy,a,hhmmss.ss,A
+		if len(fields) < 6 {
+			return ErrNMEAInvalid
+		}
+		if n.Lat, n.Lon, err = parseNMEALatLon(fields[1:5]); err != nil {
+			return
+		}
+		if n.Time, err = parseNMEATime(fields[5], "", now); err != nil {
+			return
+		}
+		n.Valid = len(fields) < 7 || fields[6] == "A"
+	}
+
+	return
+}
+
+// PositionReport returns a position report, with the car symbol, for
+// the fix including the course and speed, and altitude, if they were
+// sent.
+func (n NMEA) PositionReport() (p PositionReport) {
+	p.Lat, p.Lon = n.Lat, n.Lon
+	p.Symbol = "/>"
+	if strings.HasSuffix(n.Type, "RMC") {
+		p.CSExtension(aprsCourse(n.Course), int(math.Round(n.Speed)), 0, 0)
+	}
+	if strings.HasSuffix(n.Type, "GGA") {
+		p.Altitude = int(math.Round(n.Altitude * 3.28084))
+	}
+
+	return
+}
+
+// NMEA returns an RMC sentence for the position report.  The course and
+// speed come from a CSE/SPD data extension and the time is the timestamp
+// or, if it's zero, now.
+func (p *PositionReport) NMEA() (n NMEA, err error) {
+	t := p.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	t = t.In(time.UTC)
+
+	// An unknown course is sent empty and north is sent as 0.
+	var speed, course string
+	if c, sp, ok := p.csCourseSpeed(); ok {
+		speed = fmt.Sprintf("%.1f", float64(sp))
+		if c > 0 {
+			course = fmt.Sprintf("%.1f", float64(c%360))
+		}
+	}
+
+	lat, latHem := nmeaDegrees(p.Lat, 2, [2]string{"N", "S"})
+	lon, lonHem := nmeaDegrees(p.Lon, 3, [2]string{"E", "W"})
+	body := fmt.Sprintf("GPRMC,%s,A,%s,%s,%s,%s,%s,%s,%s,,",
+		t.Format("150405"),
+		lat, latHem,
+		lon, lonHem,
+		speed, course,
+		t.Format("020106"))
+
+	err = n.FromString(fmt.Sprintf("$%s*%02X", body, nmeaChecksum(body)))
+
+	return
+}
+
+// nmeaDegrees returns a latitude or longitude as degrees and minutes to
+// ten-thousandths of a minute, and its hemisphere.  It's rounded before
+// splitting the degrees so the minutes can't round up to 60.
+func nmeaDegrees(l float64, degLen int, hems [2]string) (string, string) {
+	m := int(math.Round(math.Abs(l) * 600000.0))
+	v := fmt.Sprintf("%0*d%02d.%04d", degLen, m/600000, m%600000/10000, m%10000)
+
+	if l < 0 {
+		return v, hems[1]
+	}
+	return v, hems[0]
+}
+
+// aprsCourse returns the course for a CSE/SPD data extension from an NMEA
+// course over ground.  North is 360 since 0 means the course is unknown.
+func aprsCourse(course float64) int {
+	if course < 0.0 {
+		return 0
+	}
+	if c := int(math.Round(course)) % 360; c > 0 {
+		return c
+	}
+
+	return 360
+}
+
+// nmeaChecksum returns the XOR of the bytes between the $ and *.
+func nmeaChecksum(body string) (c byte) {
+	for i := 0; i < len(body); i++ {
+		c ^= body[i]
+	}
+
+	return
+}
+
+// nmeaFields validates the sentence and checksum and returns the comma
+// separated fields.
+func nmeaFields(s string) ([]string, error) {
+	s = strings.TrimRight(s, "\r\n")
+	if len(s) < 2 || s[0] != '$' {
+		return nil, ErrNMEAInvalid
+	}
+	body := s[1:]
+
+	if i := strings.IndexByte(body, '*'); i >= 0 {
+		c, err := strconv.ParseUint(body[i+1:], 16, 8)
+		if err != nil || len(body[i+1:]) != 2 || byte(c) != nmeaChecksum(body[:i]) {
+			return nil, ErrNMEAInvalid
+		}
+		body = body[:i]
+	}
+
+	return strings.Split(body, ","), nil
+}
+
+// parseNMEALatLon returns the latitude and longitude from the
+// llll.ll,a,yyyyy.yy,a fields.
+func parseNMEALatLon(f []string) (lat, lon float64, err error) {
+	if lat, err = parseNMEADegrees(f[0], f[1], 2, "N", "S"); err != nil {
+		return
+	}
+	lon, err = parseNMEADegrees(f[2], f[3], 3, "E", "W")
+
+	return
+}
+
+// parseNMEADegrees returns the decimal degrees from a degrees and minutes
+// value and hemisphere.  An empty value, which means there's no fix, is
+// 0.
+func parseNMEADegrees(v, hem string, degLen int, pos, neg string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if len(v) < degLen+2 {
+		return 0, ErrNMEAInvalid
+	}
+
+	deg, err := strconv.Atoi(v[:degLen])
+	if err != nil {
+		return 0, ErrNMEAInvalid
+	}
+	min, err := strconv.ParseFloat(v[degLen:], 64)
+	if err != nil || min >= 60.0 {
+		return 0, ErrNMEAInvalid
+	}
+	l := float64(deg) + min/60.0
+
+	switch hem {
+	case pos:
+	case neg:
+		l = -l
+	default:
+		return 0, ErrNMEAInvalid
+	}
+
+	return l, nil
+}
+
+// parseNMEATime returns the UTC time from hhmmss.ss and, if available,
+// ddmmyy fields.  Without a date the most recent matching time before
+// now is used.  An empty time, which means there's no fix, is zero.
+func parseNMEATime(hms, dmy string, now time.Time) (t time.Time, err error) {
+	if hms == "" {
+		return
+	}
+	if len(hms) < 6 {
+		return t, ErrNMEAInvalid
+	}
+
+	var f [3]int
+	for i := range f {
+		if f[i], err = strconv.Atoi(hms[i*2 : i*2+2]); err != nil {
+			return t, ErrNMEAInvalid
+		}
+	}
+	var frac float64
+	if len(hms) > 6 {
+		if frac, err = strconv.ParseFloat("0"+hms[6:], 64); err != nil {
+			return t, ErrNMEAInvalid
+		}
+	}
+	ns := int(frac * float64(time.Second))
+
+	if dmy != "" {
+		var d [3]int
+		if len(dmy) != 6 {
+			return t, ErrNMEAInvalid
+		}
+		for i := range d {
+			if d[i], err = strconv.Atoi(dmy[i*2 : i*2+2]); err != nil {
+				return t, ErrNMEAInvalid
+			}
+		}
+		// Two digit years from 80 are in the 1900s.
+		year := 2000 + d[2]
+		if d[2] >= 80 {
+			year -= 100
+		}
+		return time.Date(year, time.Month(d[1]), d[0], f[0], f[1], f[2], ns, time.UTC), nil
+	}
+
+	t = time.Date(now.Year(), now.Month(), now.Day(), f[0], f[1], f[2], ns, time.UTC)
+	if t.After(now.Add(time.Minute)) {
+		t = t.AddDate(0, 0, -1)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExamplePositionReport_NMEA() {
+	p := PositionReport{
+		Timestamp: time.Date(2016, time.November, 5, 20, 35, 12, 0, time.UTC),
+		Lat:       35.7,
+		Lon:       -78.7,
+	}
+	p.CSExtension(90, 25, 0, 0)
+	n, err := p.NMEA()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(n)
+
+	// Output:
+	// $GPRMC,203512,A,3542.0000,N,07842.0000,W,25.0,90.0,051116,,*0D
+}
+
+func TestNMEAFromString(t *testing.T) {
+	a := assert.New(t)
+
+	n := NMEA{}
+	a.Nil(n.FromString("$GPRMC,063909,A,3349.4302,N,11700.3721,W,43.022,89.3,291099,13.6,E*52"), "Valid RMC")
+	a.Equal("GPRMC", n.Type, "Type")
+	a.True(n.Valid, "Valid fix")
+	a.Equal(time.Date(1999, time.October, 29, 6, 39, 9, 0, time.UTC), n.Time, "Time")
+	a.InDelta(33.823837, n.Lat, 0.000001, "Latitude")
+	a.InDelta(-117.006202, n.Lon, 0.000001, "Longitude")
+	a.Equal(43.022, n.Speed, "Speed")
+	a.Equal(89.3, n.Course, "Course")
+
+	p := n.PositionReport()
+	a.Equal("089/043", p.Extn, "Course/speed")
+	a.Equal(n.Lat, p.Lat, "Position latitude")
+
+	a.Nil(n.FromString("$GPRMC,063909,A,3349.4302,N,11700.3721,W,12.0,0.0,291099,,"), "Valid RMC heading north")
+	a.Equal("360/012", n.PositionReport().Extn, "North course/speed")
+	a.Nil(n.FromString("$GPRMC,063909,A,3349.4302,N,11700.3721,W,12.0,,291099,,"), "Valid RMC without course")
+	a.Equal(-1.0, n.Course, "Unknown course")
+	a.Equal("000/012", n.PositionReport().Extn, "Unknown course/speed")
+
+	a.Nil(n.FromString("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"), "Valid GGA")
+	a.Equal("GPGGA", n.Type, "Type")
+	a.True(n.Valid, "Valid fix")
+	a.Equal(1, n.Quality, "Fix quality")
+	a.Equal(8, n.Sats, "Satellites")
+	a.Equal(545.4, n.Altitude, "Altitude")
+	a.InDelta(48.1173, n.Lat, 0.000001, "Latitude")
+	a.InDelta(11.516667, n.Lon, 0.000001, "Longitude")
+	a.Equal(12, n.Time.Hour(), "Time")
+	a.Equal(1789, n.PositionReport().Altitude, "Position altitude in feet")
+
+	a.Nil(n.FromString("$GNGLL,4916.45,N,12311.12,W,225444,A"), "Valid GLL without checksum")
+	a.Equal("GNGLL", n.Type, "Type")
+	a.True(n.Valid, "Valid fix")
+	a.InDelta(-123.185333, n.Lon, 0.000001, "Longitude")
+
+	a.Nil(n.FromString("$GPGGA,,,,,,0,00,,,M,,M,,*66"), "Valid GGA without a fix")
+	a.False(n.Valid, "No fix")
+
+	a.Nil(n.FromString("$PGRMZ,246,f,3*1B"), "Valid unsupported sentence")
+	a.Equal("PGRMZ", n.Type, "Type")
+
+	for _, s := range []string{
+		"GPRMC,063909,A",
+		"$GPRMC,063909,A,3349.4302,N,11700.3721,W,43.022,89.3,291099,13.6,E*53",
+		"$GPRMC,063909,A,3349.4302,N,11700.3721,W,43.022,89.3,291099,13.6,E*5",
+		"$GPRMC,063909,A,3349.4302,X,11700.3721,W,43.022,89.3,291099,13.6,E",
+		"$GPGGA,123519,4807.038,N",
+	} {
+		a.Equal(ErrNMEAInvalid, n.FromString(s), "Invalid sentence %s", s)
+	}
+}
+
+func TestNMEARoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	p := PositionReport{
+		Timestamp: time.Date(2016, time.November, 5, 20, 35, 12, 0, time.UTC),
+		Lat:       -46.071795,
+		Lon:       169.6652273,
+	}
+	p.CSExtension(270, 8, 0, 0)
+
+	n, err := p.NMEA()
+	a.Nil(err, "Valid sentence")
+	a.Equal(p.Timestamp, n.Time, "Time")
+	a.InDelta(p.Lat, n.Lat, 0.000001, "Latitude")
+	a.InDelta(p.Lon, n.Lon, 0.000001, "Longitude")
+	a.Equal(p.Extn, n.PositionReport().Extn, "Course/speed")
+
+	// Minutes that round up carry into the degrees.
+	p.Lat, p.Lon = 35.9999999, -78.9999999
+	p.CSExtension(360, 12, 0, 0)
+	n, err = p.NMEA()
+	a.Nil(err, "Valid sentence rounding up to whole degrees")
+	a.Contains(n.Sentence, ",3600.0000,N,07900.0000,W,12.0,0.0,", "Sentence")
+	a.Equal("360/012", n.PositionReport().Extn, "North course/speed")
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 11: Object and Item Reports
+
+// Object represents an APRS object report.  Objects always have a
+// timestamp and a fixed-length 9 byte name.
+type Object struct {
+	Name   string // 9 byte name
+	Killed bool   // object is killed rather than live
+	PositionReport
+}
+
+// Item represents an APRS item report.  Items are like objects but
+// have a variable-length 3-9 byte name and no timestamp.
+type Item struct {
+	Name   string // 3-9 byte name
+	Killed bool   // item is killed rather than live
+	PositionReport
+}
+
+// String returns a rendered object report suitable for sending to a TNC.
+// The position is rendered the same as a PositionReport, including the
+// compressed format, data extension, Freq, altitude, and comment.
+// A zero timestamp is rendered as the current time.
+func (o *Object) String() string {
+	// Objects always have a timestamp
+	if o.Timestamp.IsZero() {
+		c := *o
+		c.Timestamp = time.Now()
+		return c.String()
+	}
+
+	return truncate(fmt.Sprintf(";%-9s%c%s%s",
+		o.Name,
+		liveKilled(o.Killed, '*'),
+		o.renderTimestamp(),
+		o.renderBody()))
+}
+
+// Validate returns an error if the object name or frequency doesn't
+// conform to the specification.
+func (o *Object) Validate() error {
+	if len(o.Name) < 1 || len(o.Name) > 9 || !printable(o.Name) {
+		return ErrObjName
+	}
+	if o.Freq != nil {
+		return o.Freq.Validate()
+	}
+
+	return nil
+}
+
+// String returns a rendered item report suitable for sending to a TNC.
+func (i *Item) String() string {
+	return truncate(fmt.Sprintf(")%s%c%s",
+		i.Name,
+		liveKilled(i.Killed, '!'),
+		i.renderBody()))
+}
+
+// Validate returns an error if the item name or frequency doesn't
+// conform to the specification.
+func (i *Item) Validate() error {
+	if len(i.Name) < 3 || len(i.Name) > 9 || !printable(i.Name) || strings.ContainsAny(i.Name, "!_") {
+		return ErrItemName
+	}
+	if i.Freq != nil {
+		return i.Freq.Validate()
+	}
+
+	return nil
+}
+
+// liveKilled returns the live indicator or the killed indicator '_'.
+func liveKilled(killed bool, live byte) byte {
+	if killed {
+		return '_'
+	}
+	return live
+}
+
+// printable returns true if s only contains printable ASCII.
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FromString sets the object from a rendered object report.
+func (o *Object) FromString(s string) (err error) {
+	*o = Object{}
+
+	// ;NNNNNNNNN*DDHHMMz
+	if len(s) < 18 || s[0] != ';' {
+		return ErrObjInvalid
+	}
+	o.Name = strings.TrimRight(s[1:10], " ")
+	switch s[10] {
+	case '*':
+	case '_':
+		o.Killed = true
+	default:
+		return ErrObjInvalid
+	}
+
+	o.Timestamp, err = parseTimestamp(s[11:18], time.Now())
+	if err != nil {
+		return
+	}
+
+	return o.parseBody(s[18:])
+}
+
+// FromString sets the item from a rendered item report.
+func (i *Item) FromString(s string) (err error) {
+	*i = Item{}
+
+	// )NNN!
+	if len(s) < 1 || s[0] != ')' {
+		return ErrItemInvalid
+	}
+	n := strings.IndexAny(s, "!_")
+	if n < 4 || n > 10 {
+		return ErrItemInvalid
+	}
+	i.Name = s[1:n]
+	i.Killed = s[n] == '_'
+
+	return i.parseBody(s[n+1:])
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleObject_String() {
+	o := Object{
+		Name: "LEADER",
+		PositionReport: PositionReport{
+			Timestamp: time.Date(2016, time.November, 9, 23, 45, 0, 0, time.UTC),
+			Lat:       49.058333,
+			Lon:       -72.029167,
+			Symbol:    "/>",
+			Comment:   "Net control",
+		},
+	}
+	o.CSExtension(88, 36, 0, 0)
+	fmt.Println(&o)
+
+	o.Killed = true
+	fmt.Println(&o)
+
+	o.Killed = false
+	o.Compressed = true
+	fmt.Println(&o)
+
+	// Output:
+	// ;LEADER   *092345z4903.50N/07201.75W>088/036Net control
+	// ;LEADER   _092345z4903.50N/07201.75W>088/036Net control
+	// ;LEADER   *092345z/5`=k<;>w>7P[Net control
+}
+
+func ExampleItem_String() {
+	i := Item{
+		Name: "AID #2",
+		PositionReport: PositionReport{
+			Lat:    49.058333,
+			Lon:    -72.029167,
+			Symbol: "/A",
+		},
+	}
+	i.PHGExtension(5, 1, 8, '3')
+	fmt.Println(&i)
+
+	i.Killed = true
+	fmt.Println(&i)
+
+	// Output:
+	// )AID #2!4903.50N/07201.75WAPHG5318
+	// )AID #2_4903.50N/07201.75WAPHG5318
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	want := Object{
+		Name: "REPEATER",
+		PositionReport: PositionReport{
+			Timestamp: time.Now().UTC().Truncate(time.Minute),
+			Lat:       35.7,
+			Lon:       -78.7,
+			Symbol:    "/r",
+			Altitude:  350,
+			Comment:   "Open",
+		},
+	}
+
+	got := Object{}
+	assert.Nil(t, got.FromString(want.String()), "Valid object")
+	assert.Equal(t, want.String(), got.String(), "Round trip")
+	assert.Equal(t, want.Name, got.Name, "Name")
+	assert.Equal(t, want.Altitude, got.Altitude, "Altitude")
+	assert.True(t, want.Timestamp.Equal(got.Timestamp), "Timestamp")
+
+	for _, s := range []string{
+		"",
+		";LEADER",
+		";LEADER   #092345z4903.50N/07201.75W>",
+		";LEADER   *092345z4903.50N/07201.75",
+	} {
+		assert.NotNil(t, got.FromString(s), "Invalid object %q", s)
+	}
+}
+
+func TestItemRoundTrip(t *testing.T) {
+	want := Item{
+		Name: "MEDIC",
+		PositionReport: PositionReport{
+			Lat:        35.7,
+			Lon:        -78.7,
+			Symbol:     "/+",
+			Compressed: true,
+		},
+	}
+
+	got := Item{}
+	assert.Nil(t, got.FromString(want.String()), "Valid item")
+	assert.Equal(t, want.String(), got.String(), "Round trip")
+	assert.Equal(t, want.Name, got.Name, "Name")
+
+	for _, s := range []string{
+		"",
+		")AB!4903.50N/07201.75WA",
+		")ABCDEFGHIJ!4903.50N/07201.75WA",
+		")AID #2*4903.50N/07201.75WA",
+	} {
+		assert.NotNil(t, got.FromString(s), "Invalid item %q", s)
+	}
+}
+
+func TestObjectStringTimestamp(t *testing.T) {
+	o := Object{Name: "LEADER", PositionReport: PositionReport{Lat: 49.058333, Lon: -72.029167, Symbol: "/>"}}
+	assert.Regexp(t, `^;LEADER   \*[0-9]{6}z4903\.50N`, o.String(), "Current timestamp")
+	assert.True(t, o.Timestamp.IsZero(), "Timestamp unchanged")
+}
+
+func TestObjectValidate(t *testing.T) {
+	assert.Nil(t, (&Object{Name: "LEADER"}).Validate(), "Valid object name")
+	assert.Equal(t, ErrObjName, (&Object{}).Validate(), "Empty object name")
+	assert.Equal(t, ErrObjName, (&Object{Name: "ABCDEFGHIJ"}).Validate(), "Long object name")
+
+	assert.Nil(t, (&Item{Name: "AID #2"}).Validate(), "Valid item name")
+	assert.Equal(t, ErrItemName, (&Item{Name: "AB"}).Validate(), "Short item name")
+	assert.Equal(t, ErrItemName, (&Item{Name: "AID_2"}).Validate(), "Item name with _")
+
+	o := Object{Name: "W1AW-R"}
+	o.Freq = &Freq{Mhz: 146.94, Tone: 88, Offset: -60}
+	assert.Nil(t, o.Validate(), "Valid repeater object")
+	o.Freq.Tone = 89
+	assert.Equal(t, ErrFreqTone, o.Validate(), "Repeater object with nonstandard tone")
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObjectManager defaults.
+const (
+	DefaultObjRate    = 8 * time.Second
+	DefaultObjMaxRate = 10 * time.Minute
+	DefaultObjKills   = 3
+)
+
+// ObjectManager owns a set of objects and items and re-beacons them on
+// a decaying schedule: each is transmitted immediately when set, then
+// the interval doubles from Rate up to MaxRate.  Killed objects and
+// items are transmitted Kills times on the same schedule and then
+// forgotten.
+//
+// Objects and items are separate namespaces, so an object and an item
+// may have the same name.  Trailing spaces in names are ignored.
+//
+// Transmissions happen on calls to Tick, which Run calls every second.
+type ObjectManager struct {
+	Src    Addr   // our address
+	Dst    Addr   // destination address; defaults to APRS
+	Path   Path   // digipath
+	Sender Sender // transport
+
+	Rate    time.Duration    // initial re-beacon interval; defaults to DefaultObjRate
+	MaxRate time.Duration    // maximum re-beacon interval; defaults to DefaultObjMaxRate
+	Kills   int              // number of killed transmissions; defaults to DefaultObjKills
+	Now     func() time.Time // clock; defaults to time.Now
+
+	// OnError, if set, is called with the name and error when a
+	// transmission fails.  Failed transmissions aren't retried early;
+	// they're sent again on the normal schedule.
+	OnError func(name string, err error)
+
+	mu      sync.Mutex
+	managed map[objKey]*managedObj
+}
+
+// objKey identifies a managed object or item.
+type objKey struct {
+	item bool
+	name string
+}
+
+// newObjKey returns the key for the named object or item.
+func newObjKey(item bool, name string) objKey {
+	return objKey{item: item, name: strings.TrimRight(name, " ")}
+}
+
+// managedObj is an object or item being beaconed.
+type managedObj struct {
+	obj      *Object // exactly one of obj or item is set
+	item     *Item
+	interval time.Duration
+	next     time.Time
+	kills    int
+}
+
+// SetObject adds or updates an object, keyed by name, and transmits
+// it immediately.  A zero timestamp is filled in with the current time
+// on every transmission.
+func (om *ObjectManager) SetObject(o Object) error {
+	o.Killed = false
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	om.set(newObjKey(false, o.Name), &managedObj{obj: &o})
+
+	return nil
+}
+
+// SetItem adds or updates an item, keyed by name, and transmits it
+// immediately.
+func (om *ObjectManager) SetItem(i Item) error {
+	i.Killed = false
+	if err := i.Validate(); err != nil {
+		return err
+	}
+
+	om.set(newObjKey(true, i.Name), &managedObj{item: &i})
+
+	return nil
+}
+
+// KillObject deletes the named object by transmitting a killed version
+// of it.  It returns false if the object isn't managed.
+func (om *ObjectManager) KillObject(name string) bool {
+	return om.kill(newObjKey(false, name))
+}
+
+// KillItem deletes the named item by transmitting a killed version of
+// it.  It returns false if the item isn't managed.
+func (om *ObjectManager) KillItem(name string) bool {
+	return om.kill(newObjKey(true, name))
+}
+
+// Names returns the names of the live objects and items.
+func (om *ObjectManager) Names() (names []string) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	for k, mo := range om.managed {
+		if mo.kills == 0 {
+			names = append(names, k.name)
+		}
+	}
+	sort.Strings(names)
+
+	return
+}
+
+// Tick transmits the objects and items that are due.
+func (om *ObjectManager) Tick() {
+	om.mu.Lock()
+	var names []string
+	var frames []Frame
+	now := om.now()
+	for k, mo := range om.managed {
+		if !now.Before(mo.next) {
+			names = append(names, k.name)
+			frames = append(frames, om.schedule(k, mo, now))
+		}
+	}
+	om.mu.Unlock()
+
+	for i, f := range frames {
+		om.transmit(names[i], f)
+	}
+}
+
+// Run ticks every second until the context is canceled.
+func (om *ObjectManager) Run(ctx context.Context) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			om.Tick()
+		}
+	}
+}
+
+// set replaces the object or item and transmits it.
+func (om *ObjectManager) set(k objKey, mo *managedObj) {
+	om.mu.Lock()
+	if om.managed == nil {
+		om.managed = map[objKey]*managedObj{}
+	}
+	om.managed[k] = mo
+	f := om.schedule(k, mo, om.now())
+	om.mu.Unlock()
+
+	om.transmit(k.name, f)
+}
+
+// kill marks the object or item killed and transmits it.
+func (om *ObjectManager) kill(k objKey) bool {
+	om.mu.Lock()
+	mo, ok := om.managed[k]
+	if !ok || mo.kills > 0 {
+		om.mu.Unlock()
+		return ok
+	}
+	if mo.obj != nil {
+		mo.obj.Killed = true
+	} else {
+		mo.item.Killed = true
+	}
+	mo.kills = om.kills()
+	mo.interval = 0
+	f := om.schedule(k, mo, om.now())
+	om.mu.Unlock()
+
+	om.transmit(k.name, f)
+
+	return true
+}
+
+// schedule schedules the next transmission of the object or item and
+// returns the frame to send now.  Killed objects and items are
+// forgotten once they've been sent enough times.  The lock must be
+// held.
+func (om *ObjectManager) schedule(k objKey, mo *managedObj, now time.Time) Frame {
+	var text string
+	if mo.obj != nil {
+		o := *mo.obj
+		if o.Timestamp.IsZero() {
+			o.Timestamp = now
+		}
+		text = o.String()
+	} else {
+		text = mo.item.String()
+	}
+
+	if mo.kills > 0 {
+		mo.kills--
+		if mo.kills == 0 {
+			delete(om.managed, k)
+		}
+	}
+
+	if mo.interval == 0 {
+		mo.interval = om.rate()
+	} else {
+		mo.interval = min(mo.interval*2, om.maxRate())
+	}
+	mo.next = now.Add(mo.interval)
+
+	dst := om.Dst
+	if dst.Call == "" {
+		dst = Addr{Call: "APRS"}
+	}
+
+	return Frame{Dst: dst, Src: om.Src, Path: om.Path, Text: text}
+}
+
+// transmit sends the frame and reports a failure to OnError.  The lock
+// must not be held since sending may block on the network.
+func (om *ObjectManager) transmit(name string, f Frame) {
+	if err := om.Sender.Send(f); err != nil && om.OnError != nil {
+		om.OnError(name, err)
+	}
+}
+
+func (om *ObjectManager) now() time.Time {
+	if om.Now == nil {
+		return time.Now()
+	}
+	return om.Now()
+}
+
+func (om *ObjectManager) rate() time.Duration {
+	if om.Rate <= 0 {
+		return DefaultObjRate
+	}
+	return om.Rate
+}
+
+func (om *ObjectManager) maxRate() time.Duration {
+	if om.MaxRate <= 0 {
+		return DefaultObjMaxRate
+	}
+	return om.MaxRate
+}
+
+func (om *ObjectManager) kills() int {
+	if om.Kills <= 0 {
+		return DefaultObjKills
+	}
+	return om.Kills
+}
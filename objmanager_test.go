@@ -0,0 +1,102 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectManager(t *testing.T) {
+	a := assert.New(t)
+
+	var sent []string
+	now := time.Date(2016, time.November, 9, 23, 45, 0, 0, time.UTC)
+	om := &ObjectManager{
+		Src: Addr{Call: "N0CALL"},
+		Sender: SenderFunc(func(f Frame) error {
+			sent = append(sent, f.Text)
+			return nil
+		}),
+		Rate:    10 * time.Second,
+		MaxRate: 40 * time.Second,
+		Kills:   2,
+		Now:     func() time.Time { return now },
+	}
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		om.Tick()
+	}
+
+	a.Nil(om.SetObject(Object{Name: "LEADER", PositionReport: PositionReport{Lat: 49.058333, Lon: -72.029167, Symbol: "/>"}}), "Set object")
+	a.Nil(om.SetItem(Item{Name: "AID #2", PositionReport: PositionReport{Lat: 49.058333, Lon: -72.029167, Symbol: "/A"}}), "Set item")
+	a.Equal(ErrObjName, om.SetObject(Object{Name: "ABCDEFGHIJ"}), "Invalid object")
+	a.Equal(ErrItemName, om.SetItem(Item{Name: "AB"}), "Invalid item")
+	a.Equal([]string{"AID #2", "LEADER"}, om.Names(), "Names")
+	a.Equal([]string{
+		";LEADER   *092345z4903.50N/07201.75W>",
+		")AID #2!4903.50N/07201.75WA",
+	}, sent, "Initial transmissions")
+
+	// Decaying schedule: 10s, 20s, 40s, 40s.
+	sent = nil
+	for _, d := range []time.Duration{9, 1, 19, 1, 39, 1, 39, 1} {
+		advance(d * time.Second)
+	}
+	a.Len(sent, 8, "Re-beacons")
+	a.Contains(sent, ";LEADER   *092346z4903.50N/07201.75W>", "Timestamp updated")
+
+	// Update in place
+	sent = nil
+	a.Nil(om.SetObject(Object{Name: "LEADER", PositionReport: PositionReport{Lat: 49.058333, Lon: -72.029167, Symbol: "/>", Comment: "Moved"}}), "Update object")
+	a.Equal([]string{";LEADER   *092346z4903.50N/07201.75W>Moved"}, sent, "Update transmission")
+
+	// Kill
+	sent = nil
+	a.False(om.KillItem("LEADER"), "Kill object as item")
+	a.True(om.KillObject("LEADER"), "Kill")
+	a.False(om.KillObject("NONE"), "Kill unknown")
+	a.Equal([]string{"AID #2"}, om.Names(), "Names after kill")
+	advance(10 * time.Second)
+	a.Equal([]string{
+		";LEADER   _092346z4903.50N/07201.75W>Moved",
+		";LEADER   _092347z4903.50N/07201.75W>Moved",
+	}, sent[:2], "Killed transmissions")
+	sent = nil
+	advance(time.Hour)
+	a.Equal([]string{")AID #2!4903.50N/07201.75WA"}, sent, "Killed object forgotten")
+}
+
+func TestObjectManagerNames(t *testing.T) {
+	a := assert.New(t)
+
+	var sent []string
+	var errs []string
+	om := &ObjectManager{
+		Sender: SenderFunc(func(f Frame) error {
+			sent = append(sent, f.Text)
+			return errors.New("send failed")
+		}),
+		OnError: func(name string, err error) {
+			errs = append(errs, name)
+		},
+	}
+
+	// Objects and items with the same name are separate and trailing
+	// spaces are ignored.
+	pos := PositionReport{Lat: 49.058333, Lon: -72.029167, Symbol: "/A"}
+	a.Nil(om.SetObject(Object{Name: "AID #2", PositionReport: pos}), "Set object")
+	a.Nil(om.SetObject(Object{Name: "AID #2   ", PositionReport: pos}), "Set padded object")
+	a.Nil(om.SetItem(Item{Name: "AID #2", PositionReport: pos}), "Set item")
+	a.Equal([]string{"AID #2", "AID #2"}, om.Names(), "Names")
+	a.Len(sent, 3, "Transmissions")
+	a.Equal([]string{"AID #2", "AID #2", "AID #2"}, errs, "Errors")
+
+	a.True(om.KillItem("AID #2 "), "Kill item")
+	a.Equal([]string{"AID #2"}, om.Names(), "Object remains")
+}
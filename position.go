@@ -2,6 +2,10 @@ package aprs
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,11 +15,15 @@ type PositionReport struct {
 	Lat            float64   // latitude
 	Lon            float64   // longitude
 	Altitude       int
-	Symbol         string // 2 byte Map symbol; see Chapter 20 aprs101
-	Extn           string // 7+ byte Data Extension field. See Chapter 7 pg27 aprs101
-	Freq           *Freq  // freqspec compatible Frequency report
-	Comment        string // free-form comment
-	MessageCapable bool   // Stations without APRS messaging capability are typically stand-alone trackers or digipeaters.
+	Symbol         string     // 2 byte Map symbol; see Chapter 20 aprs101
+	Extn           string     // 7+ byte Data Extension field. See Chapter 7 pg27 aprs101
+	Freq           *Freq      // freqspec compatible Frequency report
+	Comment        string     // free-form comment
+	MessageCapable bool       // Stations without APRS messaging capability are typically stand-alone trackers or digipeaters.
+	Ambiguity      int        // position ambiguity level (0-4); number of trailing digits blanked
+	DAO            bool       // add a !DAO! extension with an extra digit of precision
+	Compressed     bool       // render the position in the compressed base-91 format. See Chapter 9 aprs101
+	Telemetry      *Telemetry // base-91 compressed telemetry appended to the comment
 }
 
 // String returns a rendered position report suitable for sending to a TNC
@@ -30,11 +38,17 @@ func (p *PositionReport) String() string {
 		out += p.renderTimestamp()
 	}
 
+	return truncate(out + p.renderBody())
+}
+
+// renderBody returns everything following the data type and timestamp: the
+// coords, data extension, Freq, altitude, and comment
+func (p *PositionReport) renderBody() string {
 	// render the lat/long coords
-	out += p.renderCoords()
+	out := p.renderCoords()
 
 	// render the data extension block (must be at least 7 bytes)
-	if len(p.Extn) >= 7 {
+	if p.hasExtn() {
 		out += p.Extn
 	}
 
@@ -44,15 +58,29 @@ func (p *PositionReport) String() string {
 	}
 
 	// render altitude if it exists
-	if p.Altitude != 0 {
+	if p.Altitude != 0 && !(p.Compressed && p.csAltitude()) {
 		out += p.renderAltitude()
 	}
 
 	// add any other comments
 	out += p.Comment
 
+	// render the extra precision if it's wanted
+	if p.dao() {
+		out += p.renderDAO()
+	}
+
+	// render compressed telemetry if it exists
+	if p.Telemetry != nil {
+		out += p.Telemetry.renderCompressed()
+	}
+
+	return out
+}
+
+// truncate truncates a rendered report to the size of the ui frame
+func truncate(out string) string {
 	if len(out) > 255 {
-		// truncate to the size of the ui frame and return
 		return out[:255]
 	}
 	return out
@@ -79,25 +107,140 @@ func (p *PositionReport) renderTimestamp() string {
 
 // renderCoords returns the rendered latitude and longitude from the position report
 func (p *PositionReport) renderCoords() string {
+	if p.Compressed {
+		return p.renderCompressed()
+	}
+
 	sym := p.Symbol
 	if len(sym) < 2 {
 		sym = "//" // default primary table, dot
 	}
 
-	latDeg, latMin, latHem := decToDMS(p.Lat, [2]string{"N", "S"})
-	lonDeg, lonMin, lonHem := decToDMS(p.Lon, [2]string{"E", "W"})
+	lat, lon := p.coords()
+	latHem, lonHem := "N", "E"
+	if lat < 0 {
+		latHem = "S"
+	}
+	if lon < 0 {
+		lonHem = "W"
+	}
+
+	// the minutes are truncated, rather than rounded, when the DAO
+	// extension carries the next digit
+	var latStr, lonStr string
+	if p.dao() {
+		latTh, lonTh := thousandthsMinutes(lat), thousandthsMinutes(lon)
+		latStr = fmt.Sprintf("%02d%02d.%02d%s", latTh/60000, latTh%60000/1000, latTh%1000/10, latHem)
+		lonStr = fmt.Sprintf("%03d%02d.%02d%s", lonTh/60000, lonTh%60000/1000, lonTh%1000/10, lonHem)
+	} else {
+		latStr = dmsHundredths(lat, 2) + latHem
+		lonStr = dmsHundredths(lon, 3) + lonHem
+	}
+
+	// blank the ambiguous digits; the longitude follows the latitude
+	amb := min(4, max(0, p.Ambiguity))
+
+	return blankDigits(latStr, amb) + string(sym[0]) + blankDigits(lonStr, amb) + string(sym[1])
+}
+
+// coords returns the latitude and longitude to render, which is the
+// center of the ambiguity area if there is one
+func (p *PositionReport) coords() (lat, lon float64) {
+	return ambiguousCenter(p.Lat, p.Ambiguity), ambiguousCenter(p.Lon, p.Ambiguity)
+}
+
+// dao returns true if a !DAO! extension should be rendered, which is only
+// useful for precise uncompressed positions
+func (p *PositionReport) dao() bool {
+	return p.DAO && !p.Compressed && p.Ambiguity <= 0
+}
+
+// renderDAO returns the rendered human readable WGS84 !DAO! extension
+// containing the thousandths of minutes digits
+func (p *PositionReport) renderDAO() string {
+	return fmt.Sprintf("!W%d%d!", thousandthsMinutes(p.Lat)%10, thousandthsMinutes(p.Lon)%10)
+}
+
+// renderCompressed returns the rendered compressed latitude, longitude,
+// and course/speed, range, or altitude from the position report
+func (p *PositionReport) renderCompressed() string {
+	var cs string
+	if course, speed, ok := p.csCourseSpeed(); ok {
+		cs = csCourseSpeed(course, speed)
+	} else if miles, ok := p.csRange(); ok {
+		cs = csRange(miles)
+	} else if p.csAltitude() {
+		cs = csAltitude(p.Altitude)
+	}
+
+	lat, lon := p.coords()
+
+	return renderCompressed(lat, lon, p.Symbol, cs)
+}
+
+// csCourseSpeed returns the course and speed from a CSE/SPD data-extension
+// without DF data, which a compressed report carries in its cs bytes
+func (p *PositionReport) csCourseSpeed() (course, speed int, ok bool) {
+	if len(p.Extn) != 7 || p.Extn[3] != '/' {
+		return
+	}
+	var err error
+	if course, err = strconv.Atoi(p.Extn[0:3]); err != nil {
+		return
+	}
+	if speed, err = strconv.Atoi(p.Extn[4:7]); err != nil {
+		return
+	}
+
+	return course, speed, true
+}
+
+// csRange returns the range from a RNG data-extension, which a compressed
+// report carries in its cs bytes
+func (p *PositionReport) csRange() (miles int, ok bool) {
+	if len(p.Extn) != 7 || !strings.HasPrefix(p.Extn, "RNG") {
+		return
+	}
+	miles, err := strconv.Atoi(p.Extn[3:])
 
-	return fmt.Sprintf("%02.0f%05.2f%s%s%03.0f%05.2f%s%s",
-		latDeg, latMin, latHem,
-		string(sym[0]),
-		lonDeg, lonMin, lonHem,
-		string(sym[1]))
+	return miles, err == nil
+}
+
+// csAltitude returns true if the altitude is carried in the cs bytes of
+// a compressed report, which is only possible when the cs bytes aren't
+// already used and the altitude is positive
+func (p *PositionReport) csAltitude() bool {
+	if _, _, ok := p.csCourseSpeed(); ok {
+		return false
+	}
+	if _, ok := p.csRange(); ok {
+		return false
+	}
+
+	return p.Altitude > 0
+}
+
+// hasExtn returns true if a data-extension block should be rendered
+func (p *PositionReport) hasExtn() bool {
+	if len(p.Extn) < 7 {
+		return false
+	}
+	if p.Compressed {
+		if _, _, ok := p.csCourseSpeed(); ok {
+			return false
+		}
+		if _, ok := p.csRange(); ok {
+			return false
+		}
+	}
+
+	return true
 }
 
 // renderFreq returns the rendered freqspec compatible Frequency
 func (p *PositionReport) renderFreq() string {
 	// add a delimiter if a data-extension exists
-	if len(p.Extn) >= 7 {
+	if p.hasExtn() {
 		return "/" + p.Freq.Render()
 	}
 	return p.Freq.Render()
@@ -177,3 +320,338 @@ func (p *PositionReport) DFSExtension(str, gain, dir int, height byte) {
 		min(9, gain),
 		min(8, dir))
 }
+
+// AreaExtension sets the area object symbol and descriptor data-extension
+// block.  Offsets are in degrees, up to 98.01, and are rounded to the
+// nearest representable value.  The width is only used for lines.
+func (p *PositionReport) AreaExtension(t AreaType, c AreaColor, latOffset, lonOffset float64, width int) {
+	// colors 10-15 are indicated by a 1 separator
+	sep := '/'
+	if c >= 10 {
+		sep = '1'
+		c -= 10
+	}
+
+	p.Symbol = `\l`
+	p.Extn = fmt.Sprintf("%d%02d%c%d%02d",
+		min(9, max(0, int(t))),
+		areaOffset(latOffset),
+		sep,
+		min(9, max(0, int(c))),
+		areaOffset(lonOffset))
+	if t.Line() && width > 0 {
+		p.Extn += fmt.Sprintf("{%d}", min(999, width))
+	}
+}
+
+// areaOffset returns the area object offset code, which is the square
+// root of the offset in hundredths of a degree.
+func areaOffset(deg float64) int {
+	return min(99, int(math.Round(math.Sqrt(math.Abs(deg)*100.0))))
+}
+
+// reExtn matches the fixed-length 7-byte data extensions listed above,
+// except area objects which are matched by reArea.
+var reExtn = regexp.MustCompile(`^(?:[0-9. ]{3}/[0-9. ]{3}|PHG[0-9].[0-9]{2}|RNG[0-9]{4}|DFS[0-9].[0-9]{2})`)
+
+// reDF matches the 8-byte DF bearing and Number/Range/Quality
+// parameters that may follow a CSE/SPD data extension.
+var reDF = regexp.MustCompile(`^/[0-9]{3}/[0-9]{3}`)
+
+// reDAO matches a !DAO! extension: a datum followed by human readable
+// (uppercase datum) or base-91 (lowercase datum) extra precision.  It's
+// rendered at the end of the comment, ahead of any compressed telemetry,
+// so it's only matched there.
+var reDAO = regexp.MustCompile(`!([A-Z][0-9 ]{2}|[a-z][!-{]{2})!$`)
+
+// reAltitude matches an altitude in the comment text.
+var reAltitude = regexp.MustCompile(`/A=(-[0-9]{5}|[0-9]{6})`)
+
+// FromString sets the position report from a rendered position report
+// with a '!', '=', '/', or '@' data type, or an obsolete '[' grid square
+// report.
+func (p *PositionReport) FromString(s string) (err error) {
+	// Refer to APRS protocol reference 1.0
+	// Chapter 8: position and df report data formats
+	*p = PositionReport{}
+
+	if len(s) < 1 {
+		return ErrPosShort
+	}
+
+	hasTimestamp := false
+	switch s[0] {
+	case '!':
+	case '=':
+		p.MessageCapable = true
+	case '/':
+		hasTimestamp = true
+	case '@':
+		p.MessageCapable = true
+		hasTimestamp = true
+	case '[':
+		return p.parseGridSquare(s[1:])
+	default:
+		return ErrPosDataType
+	}
+	s = s[1:]
+
+	// parse the timestamp
+	if hasTimestamp {
+		if len(s) < 7 {
+			return ErrPosShort
+		}
+		p.Timestamp, err = parseTimestamp(s[:7], time.Now())
+		if err != nil {
+			return
+		}
+		s = s[7:]
+	}
+
+	return p.parseBody(s)
+}
+
+// parseGridSquare sets the position to the center of the Maidenhead
+// locator in an obsolete [IO91SX] grid square report and the comment to
+// the remaining text.
+func (p *PositionReport) parseGridSquare(s string) (err error) {
+	i := strings.IndexByte(s, ']')
+	if i != 4 && i != 6 {
+		return ErrPosInvalid
+	}
+	if p.Lat, p.Lon, err = ParseMaidenhead(s[:i]); err != nil {
+		return ErrPosInvalid
+	}
+	p.Comment = s[i+1:]
+
+	return
+}
+
+// parseBody sets everything following the data type and timestamp: the
+// coordinates, data extension, altitude, and comment.
+func (p *PositionReport) parseBody(s string) (err error) {
+	// parse the lat/long coords
+	var n int
+	if isCompressed(s) {
+		n, err = p.parseCompressed(s)
+	} else {
+		n, err = p.parseCoords(s)
+	}
+	if err != nil {
+		return
+	}
+	s = s[n:]
+
+	// parse the data extension block, unless the compressed cs bytes
+	// already provided one
+	if p.Extn == "" {
+		s = p.parseExtn(s)
+	}
+
+	// parse the frequency, which follows the data extension
+	s = p.parseFreq(s)
+
+	// parse the altitude and compressed telemetry, which may appear
+	// anywhere in the comment
+	s = p.parseAltitude(s)
+	p.Telemetry, s = parseCompressedTlm(s)
+	s = p.parseDAO(s)
+
+	p.Comment = s
+
+	return
+}
+
+// parseFreq sets the Freq if the comment begins with a freqspec
+// compatible frequency and returns the remaining comment.
+func (p *PositionReport) parseFreq(s string) string {
+	// a delimiter follows the data extension
+	t := s
+	if p.hasExtn() {
+		t = strings.TrimPrefix(s, "/")
+	}
+
+	f, rest := ParseFreq(t)
+	if f == nil {
+		return s
+	}
+	p.Freq = f
+
+	return rest
+}
+
+// parseDAO adds the extra precision from a !DAO! extension, if one ends
+// s, and returns the text with it removed.
+func (p *PositionReport) parseDAO(s string) string {
+	loc := reDAO.FindStringSubmatchIndex(s)
+	if loc == nil || p.Compressed {
+		return s
+	}
+	datum, digits := s[loc[2]], s[loc[2]+1:loc[3]]
+
+	var latMin, lonMin float64
+	if datum >= 'a' && datum <= 'z' {
+		// base-91 hundredths of hundredths of minutes
+		latMin = float64(digits[0]-33) / 91.0 * 0.01
+		lonMin = float64(digits[1]-33) / 91.0 * 0.01
+	} else {
+		// human readable thousandths of minutes, with spaces for
+		// unknown digits
+		if digits[0] != ' ' {
+			latMin = float64(digits[0]-'0') * 0.001
+		}
+		if digits[1] != ' ' {
+			lonMin = float64(digits[1]-'0') * 0.001
+		}
+	}
+	p.Lat += math.Copysign(latMin/60.0, p.Lat)
+	p.Lon += math.Copysign(lonMin/60.0, p.Lon)
+	p.DAO = true
+
+	return s[:loc[0]] + s[loc[1]:]
+}
+
+// parseCoords sets the latitude, longitude, symbol, and ambiguity from
+// uncompressed position data and returns the number of bytes consumed.
+func (p *PositionReport) parseCoords(s string) (n int, err error) {
+	// DDMM.hhN/DDDMM.hhW$
+	const coordsLen = 19
+	if len(s) < coordsLen {
+		return 0, ErrPosShort
+	}
+
+	var latAmb int
+	p.Lat, latAmb, err = parseDMS(s[0:8], 2, [2]byte{'N', 'S'})
+	if err != nil {
+		return
+	}
+	p.Lon, _, err = parseDMS(s[9:18], 3, [2]byte{'E', 'W'})
+	if err != nil {
+		return
+	}
+	// Longitude ambiguity is implied by the latitude and the position
+	// is the center of the ambiguity area.
+	p.Ambiguity = latAmb
+	if p.Ambiguity > 0 {
+		p.Lat, p.Lon = p.coords()
+	}
+	p.Symbol = string([]byte{s[8], s[18]})
+
+	return coordsLen, nil
+}
+
+// parseCompressed sets the latitude, longitude, symbol, and any course/speed,
+// range, or altitude from compressed position data and returns the number of
+// bytes consumed.
+func (p *PositionReport) parseCompressed(s string) (n int, err error) {
+	var cs string
+	p.Lat, p.Lon, p.Symbol, cs, err = parseCompressed(s)
+	if err != nil {
+		return
+	}
+	p.Compressed = true
+
+	if course, speed, rng, alt, ok := parseCS(cs); ok {
+		switch {
+		case rng > 0:
+			p.RNGExtension(rng)
+		case alt > 0:
+			p.Altitude = alt
+		default:
+			p.CSExtension(course, speed, 0, 0)
+		}
+	}
+
+	return compressedLen, nil
+}
+
+// parseExtn sets the data extension, if one leads s, and returns the
+// remaining text.
+func (p *PositionReport) parseExtn(s string) string {
+	var extn string
+	if p.isArea() {
+		extn = reArea.FindString(s)
+	} else {
+		extn = reExtn.FindString(s)
+	}
+	if extn == "" {
+		return s
+	}
+	s = s[len(extn):]
+
+	// A DF station's CSE/SPD extension may be followed by the DF bearing
+	// and NRQ.
+	if extn[3] == '/' && p.isDF() {
+		if df := reDF.FindString(s); df != "" {
+			extn += df
+			s = s[len(df):]
+		}
+	}
+	p.Extn = extn
+
+	return s
+}
+
+// isDF returns true if the report uses the DF station symbol, in which
+// case a course/speed data extension may include DF parameters.
+func (p *PositionReport) isDF() bool {
+	return len(p.Symbol) == 2 && p.Symbol[1] == '\\'
+}
+
+// parseAltitude sets the altitude, if one exists in s, and returns the
+// text with it removed.
+func (p *PositionReport) parseAltitude(s string) string {
+	loc := reAltitude.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return s
+	}
+	p.Altitude, _ = strconv.Atoi(s[loc[2]:loc[3]])
+
+	return s[:loc[0]] + s[loc[1]:]
+}
+
+// parseDMS takes an uncompressed latitude (DDMM.hhN) or longitude
+// (DDDMM.hhW) and converts it to decimal degrees.  Digits replaced by
+// spaces for position ambiguity are treated as zero and the number of
+// them is returned.
+func parseDMS(s string, degLen int, hems [2]byte) (l float64, amb int, err error) {
+	if len(s) != degLen+6 || s[degLen+2] != '.' {
+		return 0, 0, ErrPosInvalid
+	}
+
+	// Ambiguity blanks digits right to left: hundredths, then
+	// minutes.
+	digits := []byte(s[degLen : degLen+5])
+	for _, i := range []int{4, 3, 1, 0} {
+		if digits[i] != ' ' {
+			break
+		}
+		digits[i] = '0'
+		amb++
+	}
+	if strings.ContainsRune(string(digits), ' ') {
+		return 0, 0, ErrPosInvalid
+	}
+
+	var deg, min float64
+	if deg, err = strconv.ParseFloat(s[:degLen], 64); err != nil {
+		return 0, 0, ErrPosInvalid
+	}
+	if min, err = strconv.ParseFloat(string(digits), 64); err != nil {
+		return 0, 0, ErrPosInvalid
+	}
+	if min >= 60.0 {
+		return 0, 0, ErrPosInvalid
+	}
+	l = deg + min/60.0
+
+	switch s[degLen+5] {
+	case hems[0]:
+	case hems[1]:
+		l = -l
+	default:
+		return 0, 0, ErrPosInvalid
+	}
+
+	return
+}
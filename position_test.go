@@ -1,6 +1,8 @@
 package aprs
 
 import (
+	"fmt"
+	"math"
 	"testing"
 	"time"
 )
@@ -93,6 +95,48 @@ func TestRenderCoords(t *testing.T) {
 			},
 			want: `4604.31S\16939.91E#`,
 		},
+		{
+			name: "minutes rounding up to whole degrees",
+			pr: &PositionReport{
+				Lat:    45.99999,
+				Lon:    -78.99999,
+				Symbol: `/j`,
+			},
+			want: `4600.00N/07900.00Wj`,
+		},
+		{
+			name: "Wyoming DAO",
+			pr: &PositionReport{
+				Lat:    44.1083775,
+				Lon:    -107.9386725,
+				Symbol: `/j`,
+				DAO:    true,
+			},
+			want: `4406.50N/10756.32Wj`,
+		},
+	}
+	for amb, want := range []string{
+		`4406.50N/10756.32Wj`,
+		`4406.5 N/10756.3 Wj`,
+		`4406.  N/10756.  Wj`,
+		`440 .  N/1075 .  Wj`,
+		`44  .  N/107  .  Wj`,
+	} {
+		tests = append(tests, struct {
+			name string
+			pr   *PositionReport
+			want string
+		}{
+			name: fmt.Sprintf("Wyoming ambiguity %d", amb),
+			pr: &PositionReport{
+				Lat:       44.1083775,
+				Lon:       -107.9386725,
+				Symbol:    `/j`,
+				Ambiguity: amb,
+				DAO:       true,
+			},
+			want: want,
+		})
 	}
 
 	for _, tc := range tests {
@@ -192,6 +236,306 @@ func TestExtensions(t *testing.T) {
 	}
 }
 
+func TestPositionFromString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want PositionReport
+	}{
+		{
+			name: "no message, no time",
+			s:    `!4406.50N/10756.32Wj`,
+			want: PositionReport{Lat: 44.10833333333333, Lon: -107.93866666666666, Symbol: `/j`},
+		},
+		{
+			name: "yes message, extension, altitude, comment",
+			s:    `=4604.31S\16939.91E#PHG5;18/A=001234Hello world`,
+			want: PositionReport{
+				Lat:            -46.0718333333333,
+				Lon:            169.66516666666666,
+				Symbol:         `\#`,
+				Extn:           "PHG5;18",
+				Altitude:       1234,
+				Comment:        "Hello world",
+				MessageCapable: true,
+			},
+		},
+		{
+			name: "course/speed with DF",
+			s:    `!4406.50N/10756.32W\360/050/180/009 fox`,
+			want: PositionReport{Lat: 44.10833333333333, Lon: -107.93866666666666, Symbol: `/\`, Extn: "360/050/180/009", Comment: " fox"},
+		},
+		{
+			name: "course/speed without DF symbol",
+			s:    `!4406.50N/10756.32W>360/050/180/009 car`,
+			want: PositionReport{Lat: 44.10833333333333, Lon: -107.93866666666666, Symbol: `/>`, Extn: "360/050", Comment: "/180/009 car"},
+		},
+		{
+			name: "area object without DF",
+			s:    `!4406.50N\10756.32Wl715/427/180/009`,
+			want: PositionReport{Lat: 44.10833333333333, Lon: -107.93866666666666, Symbol: `\l`, Extn: "715/427", Comment: "/180/009"},
+		},
+		{
+			name: "altitude after comment",
+			s:    `!4406.50N/10756.32Wj Mobile /A=-00012`,
+			want: PositionReport{Lat: 44.10833333333333, Lon: -107.93866666666666, Symbol: `/j`, Altitude: -12, Comment: " Mobile "},
+		},
+		{
+			name: "ambiguity",
+			s:    `!49  .  N/072  .  W-`,
+			want: PositionReport{Lat: 49.5, Lon: -72.5, Symbol: `/-`, Ambiguity: 4},
+		},
+		{
+			name: "ambiguity tenths",
+			s:    `!4903.5 N/07201.7 W-`,
+			want: PositionReport{Lat: 49.05916666666667, Lon: -72.02916666666667, Symbol: `/-`, Ambiguity: 1},
+		},
+		{
+			name: "human readable DAO",
+			s:    `!4903.50N/07201.75W- rover!W27!`,
+			want: PositionReport{Lat: 49.05836666666667, Lon: -72.02928333333334, Symbol: `/-`, DAO: true, Comment: " rover"},
+		},
+		{
+			name: "base-91 DAO",
+			s:    `!4903.50N/07201.75W-!wZ!!`,
+			want: PositionReport{Lat: 49.05833333333333 + 57.0/91.0*0.01/60.0, Lon: -72.02916666666667, Symbol: `/-`, DAO: true},
+		},
+		{
+			name: "DAO in the middle of the comment",
+			s:    `!4903.50N/07201.75W-Net !X12! tonight`,
+			want: PositionReport{Lat: 49.05833333333333, Lon: -72.02916666666667, Symbol: `/-`, Comment: "Net !X12! tonight"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := PositionReport{}
+			if err := got.FromString(tc.s); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if math.Abs(got.Lat-tc.want.Lat) > 1e-9 || math.Abs(got.Lon-tc.want.Lon) > 1e-9 {
+				t.Fatalf("Wanted: %f,%f. Got %f,%f", tc.want.Lat, tc.want.Lon, got.Lat, got.Lon)
+			}
+			got.Lat, got.Lon = tc.want.Lat, tc.want.Lon
+			if got != tc.want {
+				t.Fatalf("Wanted: %+v. Got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPositionFromStringInvalid(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`>status`,
+		`!4406.50N/10756.32`,
+		`!4406.50X/10756.32Wj`,
+		`!4466.50N/10756.32Wj`,
+		`@32z4406.50N/10756.32Wj`,
+	} {
+		p := PositionReport{}
+		if err := p.FromString(s); err == nil {
+			t.Fatalf("Expected error for %q", s)
+		}
+	}
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	want := PositionReport{
+		Timestamp:      time.Now().UTC().Truncate(time.Minute),
+		Lat:            35.7,
+		Lon:            -78.7,
+		Altitude:       350,
+		Symbol:         `\j`,
+		Comment:        "Flat tire; Send beer!",
+		MessageCapable: true,
+	}
+	want.CSExtension(90, 25, 0, 0)
+
+	got := PositionReport{}
+	if err := got.FromString(want.String()); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if got.String() != want.String() {
+		t.Fatalf("Wanted: %s. Got %s", want.String(), got.String())
+	}
+	if !got.Timestamp.Equal(want.Timestamp) {
+		t.Fatalf("Wanted: %s. Got %s", want.Timestamp, got.Timestamp)
+	}
+}
+
+func TestPositionRoundTripWholeDegrees(t *testing.T) {
+	want := PositionReport{Lat: 45.99999, Lon: -78.99999, Symbol: `/j`}
+
+	got := PositionReport{}
+	if err := got.FromString(want.String()); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if got.Lat != 46.0 || got.Lon != -79.0 {
+		t.Fatalf("Wanted: 46, -79. Got %f, %f", got.Lat, got.Lon)
+	}
+}
+
+func TestPositionRoundTripPrecision(t *testing.T) {
+	for _, want := range []PositionReport{
+		{Lat: 44.1083775, Lon: -107.9386725, Symbol: `/j`, DAO: true},
+		{Lat: -46.071795, Lon: 169.6652273, Symbol: `\#`, DAO: true, Comment: "Precise"},
+		{Lat: 44.1083775, Lon: -107.9386725, Symbol: `/j`, Ambiguity: 2},
+		{Lat: 44.1083775, Lon: -107.9386725, Symbol: `/j`, Ambiguity: 3, Compressed: true},
+	} {
+		got := PositionReport{}
+		if err := got.FromString(want.String()); err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		if got.String() != want.String() {
+			t.Fatalf("Wanted: %s. Got %s", want.String(), got.String())
+		}
+		if want.DAO && (math.Abs(got.Lat-want.Lat) > 0.00001 || math.Abs(got.Lon-want.Lon) > 0.00001) {
+			t.Fatalf("Wanted: %f,%f. Got %f,%f", want.Lat, want.Lon, got.Lat, got.Lon)
+		}
+	}
+
+	p := PositionReport{Lat: 44.1083775, Lon: -107.9386725, Symbol: `/j`, DAO: true}
+	if want := "!4406.50N/10756.32Wj!W30!"; p.String() != want {
+		t.Fatalf("Wanted: %s. Got %s", want, p.String())
+	}
+}
+
+func TestRenderCompressed(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(*PositionReport)
+		want string
+	}{
+		{
+			name: "no cs",
+			op:   func(p *PositionReport) {},
+			want: `!/5L!!<*e7>  #`,
+		},
+		{
+			name: "course/speed",
+			op: func(p *PositionReport) {
+				p.CSExtension(88, 36, 0, 0)
+			},
+			want: `!/5L!!<*e7>7P[`,
+		},
+		{
+			name: "range",
+			op: func(p *PositionReport) {
+				p.RNGExtension(20)
+			},
+			want: `!/5L!!<*e7>{?C`,
+		},
+		{
+			name: "altitude",
+			op: func(p *PositionReport) {
+				p.Altitude = 10004
+			},
+			want: `!/5L!!<*e7>S]S`,
+		},
+		{
+			name: "negative altitude",
+			op: func(p *PositionReport) {
+				p.Altitude = -10
+			},
+			want: `!/5L!!<*e7>  #/A=-00010`,
+		},
+		{
+			name: "PHG",
+			op: func(p *PositionReport) {
+				p.PHGExtension(5, 1, 8, '3')
+			},
+			want: `!/5L!!<*e7>  #PHG5318`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &PositionReport{Lat: 49.5, Lon: -72.75, Symbol: "/>", Compressed: true}
+			tc.op(p)
+			if got := p.String(); got != tc.want {
+				t.Fatalf("Wanted: %s. Got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCompressed(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		extn     string
+		altitude int
+		comment  string
+	}{
+		{name: "no cs", s: `!/5L!!<*e7>  #`},
+		{name: "course/speed", s: `=/5L!!<*e7>7P[`, extn: "088/036"},
+		{name: "range", s: `=/5L!!<*e7>{?!`, extn: "RNG0020"},
+		{name: "altitude", s: `!/5L!!<*e7OS]S`, altitude: 10005},
+		{name: "comment", s: `!/5L!!<*e7>  #PHG5318 hi`, extn: "PHG5318", comment: " hi"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := PositionReport{}
+			if err := p.FromString(tc.s); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if math.Abs(p.Lat-49.5) > 1e-5 || math.Abs(p.Lon+72.75) > 1e-5 {
+				t.Fatalf("Wanted: 49.5,-72.75. Got %f,%f", p.Lat, p.Lon)
+			}
+			if !p.Compressed {
+				t.Fatalf("Wanted compressed")
+			}
+			if p.Extn != tc.extn || p.Altitude != tc.altitude || p.Comment != tc.comment {
+				t.Fatalf("Wanted: %s/%d/%s. Got %s/%d/%s", tc.extn, tc.altitude, tc.comment, p.Extn, p.Altitude, p.Comment)
+			}
+		})
+	}
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	for _, want := range []PositionReport{
+		{Lat: 49.5, Lon: -72.75, Symbol: "1>", Compressed: true},
+		{Lat: 49.5, Lon: -72.75, Symbol: "/>", Altitude: 10005, Compressed: true},
+	} {
+		got := PositionReport{}
+		if err := got.FromString(want.String()); err != nil {
+			t.Fatalf("Error decoding %s: %s", want.String(), err)
+		}
+		if got.Symbol != want.Symbol || got.Altitude != want.Altitude {
+			t.Fatalf("Wanted: %s/%d. Got %s/%d", want.Symbol, want.Altitude, got.Symbol, got.Altitude)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Date(2016, time.November, 5, 20, 35, 0, 0, time.UTC)
+	tests := []struct {
+		ts   string
+		want time.Time
+	}{
+		{"052035z", now},
+		{"051200z", time.Date(2016, time.November, 5, 12, 0, 0, 0, time.UTC)},
+		{"302359z", time.Date(2016, time.October, 30, 23, 59, 0, 0, time.UTC)},
+		{"203500h", now},
+		{"235959h", time.Date(2016, time.November, 5, 23, 59, 59, 0, time.UTC)},
+		{"083000h", time.Date(2016, time.November, 6, 8, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ts, func(t *testing.T) {
+			got, err := parseTimestamp(tc.ts, now)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Fatalf("Wanted: %s. Got %s", tc.want, got)
+			}
+		})
+	}
+}
+
 // prTimeHelper helps deal with the err returned by time.Parse()
 func prTimeHelper(t *testing.T, tString string) *PositionReport {
 	t.Helper()
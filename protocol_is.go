@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -45,47 +46,78 @@ func GenPass(call string) (pass uint16) {
 	return
 }
 
-// RecvIS receives APRS-IS frames over tcp from the specified server.
+// ISConn is a persistent APRS-IS connection which can both send
+// and receive frames.
+type ISConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	mu   sync.Mutex // serializes writes
+}
+
+// DialIS connects and logs in to the specified APRS-IS server.
 // Filter(s) are optional and use the following syntax:
 //
 // http://www.aprs-is.net/javAPRSFilter.aspx
-func RecvIS(ctx context.Context, dial string, user Addr, pass int, filters ...string) <-chan Frame {
-	fc := make(chan Frame)
+func DialIS(dial string, user Addr, pass int, filters ...string) (c *ISConn, err error) {
+	conn, err := net.Dial("tcp", dial)
+	if err != nil {
+		return
+	}
+	c = &ISConn{conn: conn, r: bufio.NewReader(conn)}
 
-	go func() {
-		defer close(fc)
+	// Read welcome banner
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = c.r.ReadString('\n'); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-		conn, err := net.Dial("tcp", dial)
-		if err != nil {
-			return
-		}
-		defer conn.Close()
-		r := bufio.NewReader(conn)
+	// Login
+	login := genLogin(user, pass)
+	if len(filters) > 0 {
+		login += " filter " + strings.Join(filters, " ")
+	}
+	if _, err = fmt.Fprintf(conn, "%s\r\n", login); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// # logresp CWxxxx unverified, server CWOP-7
+	// # logresp CWxxxx unverified, server THIRD
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = c.r.ReadString('\n'); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-		// Read welcome banner
-		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-		if _, err = r.ReadString('\n'); err != nil {
-			return
-		}
+	return
+}
 
-		// Login
-		login := genLogin(user, pass)
-		if len(filters) > 0 {
-			login += " filter " + strings.Join(filters, " ")
-		}
-		if _, err = fmt.Fprintf(conn, "%s\r\n", login); err != nil {
-			return
-		}
-		// # logresp CWxxxx unverified, server CWOP-7
-		// # logresp CWxxxx unverified, server THIRD
-		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-		if _, err = r.ReadString('\n'); err != nil {
-			return
-		}
+// Close closes the connection.
+func (c *ISConn) Close() error {
+	return c.conn.Close()
+}
+
+// Send sends a Frame over the connection.
+func (c *ISConn) Send(f Frame) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err = fmt.Fprintf(c.conn, "%s\r\n", f)
+
+	return
+}
+
+// Recv receives frames from the connection until either it's closed,
+// an error occurs, or a context cancel is received.  The channel is
+// closed when receiving stops.
+func (c *ISConn) Recv(ctx context.Context) <-chan Frame {
+	fc := make(chan Frame)
+
+	go func() {
+		defer close(fc)
 
 		// Listen for frames until either the connection is closed or a
 		// context cancel is received.
-		var s string
 		for {
 			select {
 			case <-ctx.Done():
@@ -96,8 +128,8 @@ func RecvIS(ctx context.Context, dial string, user Addr, pass int, filters ...st
 			// Heartbeats come across every 20 seconds so that's the
 			// longest the read should block.  It's also the longest
 			// it would take for a context cancel to be processed.
-			conn.SetReadDeadline(time.Now().Add(30 * time.Second))
-			s, err = r.ReadString('\n')
+			c.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+			s, err := c.r.ReadString('\n')
 			if err != nil {
 				return
 			}
@@ -106,11 +138,14 @@ func RecvIS(ctx context.Context, dial string, user Addr, pass int, filters ...st
 			// # aprsc 2.1.4-g408ed49 26 Aug 2017 16:49:48 GMT FIFTH 44.74.128.25:14580
 			if !strings.HasPrefix(s, "#") {
 				f := Frame{}
-				err = f.FromString(s)
-				if err != nil {
+				if err := f.FromString(s); err != nil {
 					continue
 				}
-				fc <- f
+				select {
+				case fc <- f:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
@@ -118,6 +153,30 @@ func RecvIS(ctx context.Context, dial string, user Addr, pass int, filters ...st
 	return fc
 }
 
+// RecvIS receives APRS-IS frames over tcp from the specified server.
+// Filter(s) are optional and use the following syntax:
+//
+// http://www.aprs-is.net/javAPRSFilter.aspx
+func RecvIS(ctx context.Context, dial string, user Addr, pass int, filters ...string) <-chan Frame {
+	fc := make(chan Frame)
+
+	go func() {
+		defer close(fc)
+
+		c, err := DialIS(dial, user, pass, filters...)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		for f := range c.Recv(ctx) {
+			fc <- f
+		}
+	}()
+
+	return fc
+}
+
 // SendIS sends a Frame to the specified APRS-IS dial string.  The
 // dial string should be in the form scheme://host:port with
 // scheme being http, tcp, or udp.  This is most commonly used for
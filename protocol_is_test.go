@@ -5,6 +5,11 @@
 package aprs
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,3 +24,43 @@ func TestGenPassOdd(t *testing.T) {
 	p := GenPass("N0CAL")
 	assert.Equal(t, 12947, int(p), "Passcode mismatch")
 }
+
+func TestISConn(t *testing.T) {
+	a := assert.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	a.Nil(err, "Listen")
+	defer l.Close()
+
+	login := make(chan string, 1)
+	sent := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		fmt.Fprintf(conn, "# aprsc test\r\n")
+		s, _ := r.ReadString('\n')
+		login <- strings.TrimSpace(s)
+		fmt.Fprintf(conn, "# logresp N0CALL verified, server TEST\r\n")
+		fmt.Fprintf(conn, "# heartbeat\r\nWU2Z>APRS::N0CALL   :ack1\r\n")
+		s, _ = r.ReadString('\n')
+		sent <- strings.TrimSpace(s)
+	}()
+
+	c, err := DialIS(l.Addr().String(), Addr{Call: "N0CALL"}, 13023, "t/m")
+	a.Nil(err, "Dial")
+	defer c.Close()
+	a.Equal("user N0CALL pass 13023 vers "+SwName+" "+SwVers+" filter t/m", <-login, "Login")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := <-c.Recv(ctx)
+	a.Equal("WU2Z>APRS::N0CALL   :ack1", f.String(), "Received frame")
+
+	a.Nil(c.Send(Frame{Src: Addr{Call: "N0CALL"}, Dst: Addr{Call: "APRS"}, Text: ">Hello"}), "Send")
+	a.Equal("N0CALL>APRS:>Hello", <-sent, "Sent frame")
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+// Sender sends frames over a transport.  ISConn is a Sender and
+// KISSSender and ISSender adapt the one-shot Frame send methods.
+type Sender interface {
+	Send(Frame) error
+}
+
+// SenderFunc is an adapter to allow the use of ordinary functions as
+// Senders.
+type SenderFunc func(Frame) error
+
+// Send calls fn(f).
+func (fn SenderFunc) Send(f Frame) error {
+	return fn(f)
+}
+
+// KISSSender returns a Sender which transmits frames to the specified
+// network TNC device using SendKISS.
+func KISSSender(dial string) Sender {
+	return SenderFunc(func(f Frame) error {
+		return f.SendKISS(dial)
+	})
+}
+
+// ISSender returns a Sender which uploads frames to the specified
+// APRS-IS dial string using SendIS.
+func ISSender(dial string, pass int) Sender {
+	return SenderFunc(func(f Frame) error {
+		return f.SendIS(dial, pass)
+	})
+}
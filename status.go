@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 16: Status Reports
+
+// StatusTextLen is the maximum length of a status report, excluding the
+// data type.
+const StatusTextLen = 62
+
+// Status represents an APRS status report.
+type Status struct {
+	Timestamp time.Time // optional timestamp; not sent with a locator
+	Locator   string    // optional 4 or 6 character Maidenhead locator
+	Symbol    string    // 2 byte map symbol, required with a locator
+	Text      string    // status text
+	Beam      *Beam     // optional beam heading and ERP
+}
+
+// Beam is a status report beam heading and effective radiated power,
+// typically from a meteor scatter station.
+type Beam struct {
+	Heading int // degrees in 10 degree steps
+	ERP     int // watts
+}
+
+// reStatusLocator matches what may be the Maidenhead locator and symbol
+// which begin a status report.  The locator is checked by
+// validStatusLocator.
+var reStatusLocator = regexp.MustCompile(`^([0-9A-Za-z]{4}(?:[0-9A-Za-z]{2})?)([/\\].)(?: |$)`)
+
+// reStatusBeam matches the beam heading and ERP which may end a status
+// report.
+var reStatusBeam = regexp.MustCompile(`\^([0-9A-Z])([0-9:;<=>?@A-Z])$`)
+
+// String returns a rendered status report suitable for sending to a TNC.
+func (st Status) String() string {
+	out := ">"
+	if st.Locator != "" {
+		out += st.Locator + st.Symbol
+		if st.Text != "" || st.Beam != nil {
+			out += " "
+		}
+	} else if !st.Timestamp.IsZero() {
+		out += st.Timestamp.In(time.UTC).Format("021504z")
+	}
+	out += st.Text
+
+	if st.Beam != nil {
+		out += "^" + st.Beam.String()
+	}
+
+	return out
+}
+
+// Validate returns an error if the locator, symbol, or text don't conform
+// to the specification.
+func (st Status) Validate() error {
+	if st.Locator != "" {
+		if m := reStatusLocator.FindStringSubmatch(st.Locator + st.Symbol); m == nil || m[1] != st.Locator || m[2] != st.Symbol || !validStatusLocator(m[1]) {
+			return ErrStatusInvalid
+		}
+	}
+	if strings.ContainsAny(st.Text, "|~") || len(st.String())-1 > StatusTextLen {
+		return ErrStatusText
+	}
+
+	return nil
+}
+
+// FromString sets the status from a rendered status report.
+func (st *Status) FromString(s string) (err error) {
+	*st = Status{}
+
+	if len(s) < 1 || s[0] != '>' {
+		return ErrStatusInvalid
+	}
+	s = s[1:]
+
+	if m := reStatusLocator.FindStringSubmatch(s); m != nil && validStatusLocator(m[1]) {
+		// A locator excludes a timestamp.
+		st.Locator, st.Symbol = m[1], m[2]
+		s = s[len(m[0]):]
+	} else if len(s) >= 7 && s[6] == 'z' {
+		// Only the zulu day/hours/minutes timestamp format is allowed.
+		if t, err := parseTimestamp(s[:7], time.Now()); err == nil {
+			st.Timestamp = t
+			s = s[7:]
+		}
+	}
+
+	if m := reStatusBeam.FindStringSubmatch(s); m != nil {
+		st.Beam = &Beam{}
+		if err = st.Beam.FromString(m[1] + m[2]); err != nil {
+			return
+		}
+		s = strings.TrimSuffix(s[:len(s)-len(m[0])], " ")
+	}
+	st.Text = s
+
+	return
+}
+
+// validStatusLocator returns true if loc is a 4 or 6 character
+// Maidenhead locator.
+func validStatusLocator(loc string) bool {
+	if len(loc) != 4 && len(loc) != 6 {
+		return false
+	}
+	_, _, err := ParseMaidenhead(loc)
+
+	return err == nil
+}
+
+// String returns the 2 character beam heading and ERP.
+func (b Beam) String() string {
+	// Heading is 0-9 for 0-90 degrees and A-Z for 100-350 degrees.
+	h := (b.Heading%360 + 360) % 360 / 10
+	hc := byte('0' + h)
+	if h > 9 {
+		hc = byte('A' + h - 10)
+	}
+
+	// ERP is the square of the character value times 10 watts.
+	p := min(42, int(math.Round(math.Sqrt(float64(max(0, b.ERP))/10.0))))
+
+	return fmt.Sprintf("%c%c", hc, '0'+p)
+}
+
+// FromString sets the beam heading and ERP from the 2 characters
+// following the ^.
+func (b *Beam) FromString(s string) error {
+	*b = Beam{}
+	if len(s) != 2 || s[0] < '0' || s[0] > 'Z' || s[1] < '0' || s[1] > 'Z' {
+		return ErrStatusInvalid
+	}
+
+	if s[0] >= 'A' {
+		b.Heading = int(s[0]-'A'+10) * 10
+	} else {
+		b.Heading = int(s[0]-'0') * 10
+	}
+	p := int(s[1] - '0')
+	b.ERP = p * p * 10
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleStatus_String() {
+	st := Status{Text: "Net Control Center"}
+	fmt.Println(st)
+
+	st.Timestamp = time.Date(2016, time.November, 9, 23, 45, 0, 0, time.UTC)
+	fmt.Println(st)
+
+	st = Status{Locator: "IO91SX", Symbol: "/G", Text: "Meteor scatter", Beam: &Beam{Heading: 110, ERP: 490}}
+	fmt.Println(st)
+
+	// Output:
+	// >Net Control Center
+	// >092345zNet Control Center
+	// >IO91SX/G Meteor scatter^B7
+}
+
+func TestStatusFromString(t *testing.T) {
+	a := assert.New(t)
+
+	st := Status{}
+	a.Nil(st.FromString(">IO91SX/G"), "Valid locator status")
+	a.Equal(Status{Locator: "IO91SX", Symbol: "/G"}, st, "Locator status")
+
+	a.Nil(st.FromString(">IO91/G My house ^B7"), "Valid 4 character locator status")
+	a.Equal(Status{Locator: "IO91", Symbol: "/G", Text: "My house", Beam: &Beam{Heading: 110, ERP: 490}}, st, "Locator status with beam")
+
+	a.Nil(st.FromString(">092345zNet Control Center^Z:"), "Valid timestamped status")
+	a.Equal("Net Control Center", st.Text, "Text")
+	a.Equal(23, st.Timestamp.Hour(), "Timestamp")
+	a.Equal(&Beam{Heading: 350, ERP: 1000}, st.Beam, "Beam")
+
+	a.Nil(st.FromString(">IO91SX is my grid"), "Valid status without symbol")
+	a.Equal("", st.Locator, "No locator")
+	a.Equal("IO91SX is my grid", st.Text, "Text")
+
+	a.Nil(st.FromString(">ZZ99/G Not a grid"), "Valid status with invalid locator")
+	a.Equal("", st.Locator, "No locator")
+	a.Equal("ZZ99/G Not a grid", st.Text, "Text")
+
+	a.Equal(ErrStatusInvalid, st.FromString("Net Control Center"), "Invalid status")
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	for _, st := range []Status{
+		{Text: "Net Control Center"},
+		{Locator: "FM05", Symbol: `\G`, Text: "Contest"},
+		{Locator: "IO91SX", Symbol: "/G", Beam: &Beam{Heading: 0, ERP: 10}},
+		{Text: "Beaming", Beam: &Beam{Heading: 270, ERP: 250}},
+	} {
+		a.Nil(st.Validate(), "Valid status %s", st)
+		got := Status{}
+		a.Nil(got.FromString(st.String()), "Valid status %s", st)
+		a.Equal(st, got, "Round trip %s", st)
+	}
+}
+
+func TestStatusValidate(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(ErrStatusInvalid, Status{Locator: "IO91SX"}.Validate(), "Locator without symbol")
+	a.Equal(ErrStatusInvalid, Status{Locator: "IO9", Symbol: "/G"}.Validate(), "Short locator")
+	a.Equal(ErrStatusInvalid, Status{Locator: "IO91SZ", Symbol: "/G"}.Validate(), "Invalid subsquare")
+	a.Equal(ErrStatusInvalid, Status{Locator: "IO91SX12", Symbol: "/G"}.Validate(), "Extended locator")
+	a.Equal(ErrStatusText, Status{Text: "Net|Control"}.Validate(), "Text with |")
+	a.Equal(ErrStatusText, Status{Locator: "IO91SX", Symbol: "/G", Text: fmt.Sprintf("%054d", 0)}.Validate(), "Long locator text")
+	a.Nil(Status{Text: fmt.Sprintf("%062d", 0)}.Validate(), "Maximum length text")
+}
@@ -0,0 +1,464 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Refer to APRS Protocol Reference 1.0
+// Chapter 13: Telemetry Data
+//
+// Telemetry reports carry raw values.  Their names, units, equations,
+// and bit senses are defined by PARM, UNIT, EQNS, and BITS messages the
+// station sends to itself.
+
+// Telemetry represents an APRS telemetry report of 5 analog channels
+// and 8 digital bits.
+type Telemetry struct {
+	Seq     int        // sequence number
+	Analog  [5]float64 // analog values A1-A5
+	Digital [8]bool    // digital values B1-B8
+	Comment string     // free-form comment
+}
+
+// String returns a rendered telemetry report suitable for sending to a
+// TNC.
+func (t Telemetry) String() string {
+	out := fmt.Sprintf("T#%03d", t.Seq)
+	for _, v := range t.Analog {
+		out += "," + formatTlmValue(v)
+	}
+	out += ","
+	for _, b := range t.Digital {
+		out += bit(b)
+	}
+
+	return out + t.Comment
+}
+
+// formatTlmValue returns the value as 3 digits if it's a whole number
+// from 0-999, otherwise as a decimal.
+func formatTlmValue(v float64) string {
+	if v >= 0 && v <= 999 && v == float64(int(v)) {
+		return z3p(int(v))
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// bit returns "1" if b is true, otherwise "0".
+func bit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// FromString sets the telemetry from a rendered telemetry report.
+func (t *Telemetry) FromString(s string) (err error) {
+	*t = Telemetry{}
+
+	// T#sss,aaa,aaa,aaa,aaa,aaa,bbbbbbbb
+	if !strings.HasPrefix(s, "T#") {
+		return ErrTlmInvalid
+	}
+	fields := strings.SplitN(s[2:], ",", 7)
+	if len(fields) != 7 {
+		return ErrTlmInvalid
+	}
+
+	// Some Mic-E devices send MIC in place of the sequence number.
+	if fields[0] != "MIC" {
+		if t.Seq, err = strconv.Atoi(fields[0]); err != nil {
+			return ErrTlmInvalid
+		}
+	}
+
+	for i := range t.Analog {
+		if t.Analog[i], err = strconv.ParseFloat(strings.TrimSpace(fields[i+1]), 64); err != nil {
+			return ErrTlmInvalid
+		}
+	}
+
+	bits := fields[6]
+	if len(bits) < 8 {
+		return ErrTlmInvalid
+	}
+	for i := range t.Digital {
+		switch bits[i] {
+		case '0':
+		case '1':
+			t.Digital[i] = true
+		default:
+			return ErrTlmInvalid
+		}
+	}
+	t.Comment = bits[8:]
+
+	return nil
+}
+
+// reTlmCompressed matches base-91 compressed telemetry in a comment:
+// a sequence number, 1-5 analog channels, and optional digital bits.
+var reTlmCompressed = regexp.MustCompile(`\|((?:[!-{]{2}){2,7})\|`)
+
+// renderCompressed returns the base-91 compressed telemetry suitable for
+// appending to a position comment.  Trailing zero analog channels are
+// omitted unless digital bits are set.
+//
+// http://he.fi/doc/aprs-base91-comment-telemetry.txt
+func (t Telemetry) renderCompressed() string {
+	const maxVal = 91*91 - 1
+
+	n := len(t.Analog)
+	if t.Digital == [8]bool{} {
+		for n > 1 && t.Analog[n-1] == 0 {
+			n--
+		}
+	}
+
+	out := "|" + encBase91(t.Seq%(maxVal+1), 2)
+	for _, v := range t.Analog[:n] {
+		out += encBase91(min(maxVal, max(0, int(math.Round(v)))), 2)
+	}
+	if n == len(t.Analog) && t.Digital != [8]bool{} {
+		bits := 0
+		for i, b := range t.Digital {
+			if b {
+				bits |= 1 << i
+			}
+		}
+		out += encBase91(bits, 2)
+	}
+
+	return out + "|"
+}
+
+// parseCompressedTlm extracts base-91 compressed telemetry from s and
+// returns it and the remaining text.
+func parseCompressedTlm(s string) (*Telemetry, string) {
+	loc := reTlmCompressed.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil, s
+	}
+
+	t := &Telemetry{}
+	vals := s[loc[2]:loc[3]]
+	for i := 0; i < len(vals); i += 2 {
+		v, _ := decBase91(vals[i : i+2])
+		switch i / 2 {
+		case 0:
+			t.Seq = v
+		case 6:
+			for b := range t.Digital {
+				t.Digital[b] = v&(1<<b) > 0
+			}
+		default:
+			t.Analog[i/2-1] = float64(v)
+		}
+	}
+
+	return t, s[:loc[0]] + s[loc[1]:]
+}
+
+// TelemetryParm is a PARM message defining the names of the analog
+// and digital channels.
+type TelemetryParm struct {
+	Station string     // station the names apply to; the message addressee
+	Names   [13]string // A1-A5 then B1-B8
+}
+
+// TelemetryUnit is a UNIT message defining the units or labels of the
+// analog and digital channels.
+type TelemetryUnit struct {
+	Station string     // station the units apply to; the message addressee
+	Labels  [13]string // A1-A5 then B1-B8
+}
+
+// TelemetryEqns is an EQNS message defining the equation coefficients
+// a, b, and c for each analog channel.  The engineering value is
+// a*v^2 + b*v + c for a raw value v.
+type TelemetryEqns struct {
+	Station string // station the equations apply to; the message addressee
+	Coeffs  [5][3]float64
+}
+
+// TelemetryBits is a BITS message defining the sense of the digital
+// channels, the state which is considered active, and a project title.
+type TelemetryBits struct {
+	Station string  // station the bits apply to; the message addressee
+	Sense   [8]bool // B1-B8
+	Project string  // project title
+}
+
+// String returns the PARM message.
+func (tp TelemetryParm) String() string {
+	return tlmMsg(tp.Station, "PARM.", tp.Names[:])
+}
+
+// FromString sets the PARM from a rendered message.
+func (tp *TelemetryParm) FromString(s string) (err error) {
+	*tp = TelemetryParm{}
+	var fields []string
+	tp.Station, fields, err = parseTlmMsg(s, "PARM.")
+	copy(tp.Names[:], fields)
+
+	return
+}
+
+// String returns the UNIT message.
+func (tu TelemetryUnit) String() string {
+	return tlmMsg(tu.Station, "UNIT.", tu.Labels[:])
+}
+
+// FromString sets the UNIT from a rendered message.
+func (tu *TelemetryUnit) FromString(s string) (err error) {
+	*tu = TelemetryUnit{}
+	var fields []string
+	tu.Station, fields, err = parseTlmMsg(s, "UNIT.")
+	copy(tu.Labels[:], fields)
+
+	return
+}
+
+// String returns the EQNS message.
+func (te TelemetryEqns) String() string {
+	var fields []string
+	for _, c := range te.Coeffs {
+		for _, v := range c {
+			fields = append(fields, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+
+	return tlmMsg(te.Station, "EQNS.", fields)
+}
+
+// FromString sets the EQNS from a rendered message.  Channels, or
+// coefficients, that aren't sent default to 0,1,0 so the raw value is
+// unchanged.
+func (te *TelemetryEqns) FromString(s string) (err error) {
+	*te = TelemetryEqns{}
+	for i := range te.Coeffs {
+		te.Coeffs[i] = [3]float64{0, 1, 0}
+	}
+	var fields []string
+	te.Station, fields, err = parseTlmMsg(s, "EQNS.")
+	if err != nil {
+		return
+	}
+	if len(fields) > 15 {
+		return ErrTlmInvalid
+	}
+	for i, f := range fields {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		if te.Coeffs[i/3][i%3], err = strconv.ParseFloat(f, 64); err != nil {
+			return ErrTlmInvalid
+		}
+	}
+
+	return
+}
+
+// String returns the BITS message.
+func (tb TelemetryBits) String() string {
+	sense := ""
+	for _, b := range tb.Sense {
+		sense += bit(b)
+	}
+
+	return tlmMsg(tb.Station, "BITS.", []string{sense, tb.Project})
+}
+
+// FromString sets the BITS from a rendered message.
+func (tb *TelemetryBits) FromString(s string) (err error) {
+	*tb = TelemetryBits{}
+	var fields []string
+	tb.Station, fields, err = parseTlmMsg(s, "BITS.")
+	if err != nil {
+		return
+	}
+	sense, project, _ := strings.Cut(strings.Join(fields, ","), ",")
+	if len(sense) != 8 {
+		return ErrTlmInvalid
+	}
+	for i := range tb.Sense {
+		switch sense[i] {
+		case '0':
+		case '1':
+			tb.Sense[i] = true
+		default:
+			return ErrTlmInvalid
+		}
+	}
+	tb.Project = project
+
+	return
+}
+
+// tlmMsg returns a telemetry definition message with trailing empty
+// fields removed.
+func tlmMsg(station, prefix string, fields []string) string {
+	n := len(fields)
+	for n > 0 && fields[n-1] == "" {
+		n--
+	}
+
+	return Message{Addressee: station, Text: prefix + strings.Join(fields[:n], ",")}.String()
+}
+
+// parseTlmMsg parses a telemetry definition message and returns the
+// station and comma separated fields.
+func parseTlmMsg(s, prefix string) (station string, fields []string, err error) {
+	m := Message{}
+	if err = m.FromString(s); err != nil {
+		return
+	}
+	if !strings.HasPrefix(m.Text, prefix) {
+		return "", nil, ErrTlmInvalid
+	}
+
+	return m.Addressee, strings.Split(m.Text[len(prefix):], ","), nil
+}
+
+// isTlmMsg returns true if the message text is a telemetry definition.
+func isTlmMsg(text string) bool {
+	for _, prefix := range []string{"PARM.", "UNIT.", "EQNS.", "BITS."} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TelemetryValue is a telemetry channel value with its name and unit
+// from the definitions.
+type TelemetryValue struct {
+	Name  string
+	Unit  string
+	Value float64 // engineering value for analog channels; 1 if active, otherwise 0, for digital channels
+}
+
+// TelemetryDefs holds the received telemetry definitions for a station.
+// Definitions that haven't been received are nil.
+type TelemetryDefs struct {
+	Station string // station the definitions apply to
+	Parm    *TelemetryParm
+	Unit    *TelemetryUnit
+	Eqns    *TelemetryEqns
+	Bits    *TelemetryBits
+}
+
+// Update sets the definition if v is a TelemetryParm, TelemetryUnit,
+// TelemetryEqns, or TelemetryBits, such as returned by Frame.Decode,
+// for the station.  It returns false for anything else, including
+// definitions for other stations.
+func (d *TelemetryDefs) Update(v any) bool {
+	station, ok := tlmDefStation(v)
+	if !ok || !strings.EqualFold(station, d.Station) {
+		return false
+	}
+
+	switch v := v.(type) {
+	case TelemetryParm:
+		d.Parm = &v
+	case TelemetryUnit:
+		d.Unit = &v
+	case TelemetryEqns:
+		d.Eqns = &v
+	case TelemetryBits:
+		d.Bits = &v
+	default:
+		return false
+	}
+
+	return true
+}
+
+// tlmDefStation returns the station a telemetry definition applies to.
+func tlmDefStation(v any) (string, bool) {
+	switch v := v.(type) {
+	case TelemetryParm:
+		return v.Station, true
+	case TelemetryUnit:
+		return v.Station, true
+	case TelemetryEqns:
+		return v.Station, true
+	case TelemetryBits:
+		return v.Station, true
+	}
+
+	return "", false
+}
+
+// TelemetryStations holds the received telemetry definitions keyed by
+// station.
+type TelemetryStations map[string]*TelemetryDefs
+
+// Update sets the definition, like TelemetryDefs.Update, for the station
+// it's addressed to.  It returns false if v isn't a definition.
+func (ts TelemetryStations) Update(v any) bool {
+	station, ok := tlmDefStation(v)
+	if !ok {
+		return false
+	}
+	k := strings.ToUpper(station)
+	if ts[k] == nil {
+		ts[k] = &TelemetryDefs{Station: station}
+	}
+
+	return ts[k].Update(v)
+}
+
+// Defs returns the definitions for the station, which is usually the
+// source of the telemetry report.
+func (ts TelemetryStations) Defs(station string) TelemetryDefs {
+	if d := ts[strings.ToUpper(station)]; d != nil {
+		return *d
+	}
+
+	return TelemetryDefs{Station: station}
+}
+
+// Apply returns the 5 analog then 8 digital channel values of the
+// telemetry report with the definitions applied.  Without equations the
+// raw analog values are returned and without bit senses a digital value
+// of 1 is active.
+func (d TelemetryDefs) Apply(t Telemetry) (vals [13]TelemetryValue) {
+	for i := range vals {
+		if d.Parm != nil {
+			vals[i].Name = d.Parm.Names[i]
+		}
+		if d.Unit != nil {
+			vals[i].Unit = d.Unit.Labels[i]
+		}
+	}
+
+	for i, v := range t.Analog {
+		if d.Eqns != nil {
+			c := d.Eqns.Coeffs[i]
+			v = c[0]*v*v + c[1]*v + c[2]
+		}
+		vals[i].Value = v
+	}
+
+	for i, b := range t.Digital {
+		sense := true
+		if d.Bits != nil {
+			sense = d.Bits.Sense[i]
+		}
+		if b == sense {
+			vals[5+i].Value = 1
+		}
+	}
+
+	return
+}
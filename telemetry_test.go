@@ -0,0 +1,144 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleTelemetry_String() {
+	t := Telemetry{
+		Seq:     5,
+		Analog:  [5]float64{199, 0, 255, 73, 12.5},
+		Digital: [8]bool{false, true, true, false, true, false, false, true},
+		Comment: "Site 1",
+	}
+	fmt.Println(t)
+
+	// Output:
+	// T#005,199,000,255,073,12.5,01101001Site 1
+}
+
+func ExampleTelemetryParm_String() {
+	fmt.Println(TelemetryParm{Station: "N0QBF-11", Names: [13]string{"Battery", "Btemp", "ATemp", "Pres", "Alt", "Camra", "Chute", "Sun", "10m", "ATV"}})
+	fmt.Println(TelemetryUnit{Station: "N0QBF-11", Labels: [13]string{"v/100", "deg.F", "deg.F", "Mbar", "Kft", "Click", "OPEN", "on", "on", "hi"}})
+	fmt.Println(TelemetryEqns{Station: "N0QBF-11", Coeffs: [5][3]float64{{0, 5.2, 0}, {0, 0.53, -32}, {3, 4.39, 49}, {-32, 3, 18}, {1, 2, 3}}})
+	fmt.Println(TelemetryBits{Station: "N0QBF-11", Sense: [8]bool{true, false, false, true, true, false, false, false}, Project: "Balloon"})
+
+	// Output:
+	// :N0QBF-11 :PARM.Battery,Btemp,ATemp,Pres,Alt,Camra,Chute,Sun,10m,ATV
+	// :N0QBF-11 :UNIT.v/100,deg.F,deg.F,Mbar,Kft,Click,OPEN,on,on,hi
+	// :N0QBF-11 :EQNS.0,5.2,0,0,0.53,-32,3,4.39,49,-32,3,18,1,2,3
+	// :N0QBF-11 :BITS.10011000,Balloon
+}
+
+func TestTelemetryRoundTrip(t *testing.T) {
+	want := Telemetry{Seq: 123, Analog: [5]float64{1, 2.25, 300, 4, 1000}, Digital: [8]bool{true}}
+	got := Telemetry{}
+	assert.Nil(t, got.FromString(want.String()), "Valid telemetry")
+	assert.Equal(t, want, got, "Telemetry")
+
+	for _, s := range []string{"", "T#", "T#005,1,2,3,4,5", "T#005,1,2,x,4,5,00000000", "T#005,1,2,3,4,5,0000", "T#005,1,2,3,4,5,0000200"} {
+		assert.Equal(t, ErrTlmInvalid, got.FromString(s), "Invalid telemetry %q", s)
+	}
+}
+
+func TestTelemetryDefs(t *testing.T) {
+	a := assert.New(t)
+
+	defs := TelemetryDefs{Station: "N0QBF-11"}
+	for _, s := range []string{
+		"N0QBF-11>APRS::N0QBF-11 :PARM.Battery,Btemp,ATemp,Pres,Alt,Camra,Chute,Sun,10m,ATV",
+		"N0QBF-11>APRS::N0QBF-11 :UNIT.v/100,deg.F,deg.F,Mbar,Kft,Click,OPEN,on,on,hi",
+		"N0QBF-11>APRS::N0QBF-11 :EQNS.0,5.2,0,0,.53,-32,3,4.39,49,-32,3,18,1,2,3",
+		"N0QBF-11>APRS::N0QBF-11 :BITS.10110000,N0QBF's Big Balloon",
+	} {
+		f := Frame{}
+		a.Nil(f.FromString(s), "Frame")
+		v, err := f.Decode()
+		a.Nil(err, "Decode %s", s)
+		a.True(defs.Update(v), "Update %s", s)
+	}
+	a.False(defs.Update(Message{}), "Update non-definition")
+	a.False(defs.Update(TelemetryBits{Station: "N0CALL"}), "Update other station")
+	a.Equal("N0QBF's Big Balloon", defs.Bits.Project, "Project")
+
+	vals := defs.Apply(Telemetry{Analog: [5]float64{1, 100, 2, 1, 0}, Digital: [8]bool{true, true, true}})
+	a.Equal(TelemetryValue{Name: "Battery", Unit: "v/100", Value: 5.2}, vals[0], "A1")
+	a.Equal(TelemetryValue{Name: "Btemp", Unit: "deg.F", Value: 21}, vals[1], "A2")
+	a.InDelta(69.78, vals[2].Value, 0.0001, "A3")
+	a.Equal(TelemetryValue{Name: "Camra", Unit: "Click", Value: 1}, vals[5], "B1")
+	a.Equal(TelemetryValue{Name: "Chute", Unit: "OPEN", Value: 0}, vals[6], "B2")
+	a.Equal(TelemetryValue{Name: "Sun", Unit: "on", Value: 1}, vals[7], "B3")
+	a.Equal(TelemetryValue{Name: "10m", Unit: "on", Value: 0}, vals[8], "B4")
+	a.Equal(TelemetryValue{Value: 1}, vals[9+3], "B8")
+
+	// Without definitions
+	vals = TelemetryDefs{}.Apply(Telemetry{Analog: [5]float64{42}, Digital: [8]bool{true}})
+	a.Equal(42.0, vals[0].Value, "Raw A1")
+	a.Equal(1.0, vals[5].Value, "Raw B1")
+
+	tb := TelemetryBits{}
+	a.Equal(ErrTlmInvalid, tb.FromString(":N0QBF-11 :BITS.101"), "Short bits")
+	te := TelemetryEqns{}
+	a.Equal(ErrTlmInvalid, te.FromString(":N0QBF-11 :EQNS.a,b,c"), "Invalid equation")
+	a.Nil(te.FromString(":N0QBF-11 :EQNS.0,5.2,0,0,.53"), "Partial equations")
+	a.Equal([5][3]float64{{0, 5.2, 0}, {0, .53, 0}, {0, 1, 0}, {0, 1, 0}, {0, 1, 0}}, te.Coeffs, "Default equations")
+}
+
+func TestTelemetryStations(t *testing.T) {
+	a := assert.New(t)
+
+	ts := TelemetryStations{}
+	a.True(ts.Update(TelemetryParm{Station: "N0QBF-11", Names: [13]string{"Battery"}}), "Update N0QBF-11")
+	a.True(ts.Update(TelemetryParm{Station: "N0CALL", Names: [13]string{"Volts"}}), "Update N0CALL")
+	a.False(ts.Update(Message{}), "Update non-definition")
+
+	a.Equal("Battery", ts.Defs("n0qbf-11").Apply(Telemetry{})[0].Name, "N0QBF-11 definitions")
+	a.Equal("Volts", ts.Defs("N0CALL").Apply(Telemetry{})[0].Name, "N0CALL definitions")
+	a.Nil(ts.Defs("N0NE").Parm, "Unknown station")
+}
+
+func ExamplePositionReport_String_telemetry() {
+	p := PositionReport{
+		Lat:       49.5,
+		Lon:       -72.75,
+		Symbol:    "/#",
+		Comment:   "Site 1",
+		Telemetry: &Telemetry{Seq: 123, Analog: [5]float64{1, 8280}},
+	}
+	fmt.Println(&p)
+
+	p.Telemetry.Digital[0] = true
+	p.Telemetry.Digital[7] = true
+	fmt.Println(&p)
+
+	// Output:
+	// !4930.00N/07245.00W#Site 1|"A!"{{|
+	// !4930.00N/07245.00W#Site 1|"A!"{{!!!!!!"G|
+}
+
+func TestParseCompressedTelemetry(t *testing.T) {
+	a := assert.New(t)
+
+	p := PositionReport{}
+	a.Nil(p.FromString(`!4930.00N/07245.00W#Site |"A!"{{!!!!!!"G| 1`), "Valid position")
+	a.Equal("Site  1", p.Comment, "Comment")
+	a.Equal(&Telemetry{
+		Seq:     123,
+		Analog:  [5]float64{1, 8280},
+		Digital: [8]bool{true, false, false, false, false, false, false, true},
+	}, p.Telemetry, "Telemetry")
+
+	a.Nil(p.FromString(`!4930.00N/07245.00W#|!!|`), "Valid position")
+	a.Equal("|!!|", p.Comment, "Too short")
+	a.Nil(p.Telemetry, "No telemetry")
+
+	a.Nil(p.FromString(`!4930.00N/07245.00W#|!!!|`), "Valid position")
+	a.Nil(p.Telemetry, "Odd length")
+}
@@ -3,6 +3,8 @@ package aprs
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"time"
 )
 
 // unexported utility functions
@@ -12,14 +14,149 @@ func z3p(i int) string {
 	return fmt.Sprintf("%03d", i)
 }
 
-// decToDMS takes a float latitude or longitude and converts it to
-// degrees, minutes (second as decimal), and a hemisphere string.
-func decToDMS(l float64, hems [2]string) (float64, float64, string) {
-	deg, frac := math.Modf(math.Abs(l))
-	min := frac * 60.0
+// encBase91 returns the n byte base-91 representation of the given int.
+func encBase91(v, n int) string {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v%91) + 33
+		v /= 91
+	}
+
+	return string(b)
+}
+
+// decBase91 returns the int value of the given base-91 string.
+func decBase91(s string) (v int, ok bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 33+90 {
+			return 0, false
+		}
+		v = v*91 + int(s[i]-33)
+	}
+
+	return v, true
+}
+
+// parseTimestamp takes a 7 byte APRS timestamp in day/hours/minutes
+// (zulu or local) or hours/minutes/seconds format and converts it to a
+// time.  The missing date fields are filled in from now, picking the
+// most recent matching time.
+func parseTimestamp(ts string, now time.Time) (t time.Time, err error) {
+	if len(ts) != 7 {
+		return t, ErrTimestamp
+	}
 
-	if l < 0 {
-		return deg, min, hems[1]
+	var f [3]int
+	for i := range f {
+		f[i], err = strconv.Atoi(ts[i*2 : i*2+2])
+		if err != nil {
+			return t, ErrTimestamp
+		}
+	}
+
+	switch ts[6] {
+	case 'z', '/':
+		loc := time.UTC
+		if ts[6] == '/' {
+			loc = time.Local
+		}
+		if f[0] < 1 || f[0] > 31 || f[1] > 23 || f[2] > 59 {
+			return t, ErrTimestamp
+		}
+		now = now.In(loc)
+		t = time.Date(now.Year(), now.Month(), f[0], f[1], f[2], 0, 0, loc)
+		// Allow for some clock skew but anything further in the
+		// future must be from last month.
+		if t.After(now.Add(24 * time.Hour)) {
+			t = time.Date(now.Year(), now.Month()-1, f[0], f[1], f[2], 0, 0, loc)
+		}
+	case 'h':
+		if f[0] > 23 || f[1] > 59 || f[2] > 59 {
+			return t, ErrTimestamp
+		}
+		now = now.In(time.UTC)
+		t = time.Date(now.Year(), now.Month(), now.Day(), f[0], f[1], f[2], 0, time.UTC)
+		if t.After(now.Add(12 * time.Hour)) {
+			t = t.AddDate(0, 0, -1)
+		} else if t.Before(now.Add(-12 * time.Hour)) {
+			t = t.AddDate(0, 0, 1)
+		}
+	default:
+		return t, ErrTimestamp
+	}
+
+	return
+}
+
+// parseTimestampMDHM takes an 8 byte month/day/hours/minutes zulu
+// timestamp, as used by positionless weather reports, and converts it
+// to a time.  The year is filled in from now, picking the most recent
+// matching time.
+func parseTimestampMDHM(ts string, now time.Time) (t time.Time, err error) {
+	if len(ts) != 8 {
+		return t, ErrTimestamp
+	}
+
+	var f [4]int
+	for i := range f {
+		f[i], err = strconv.Atoi(ts[i*2 : i*2+2])
+		if err != nil {
+			return t, ErrTimestamp
+		}
+	}
+	if f[0] < 1 || f[0] > 12 || f[1] < 1 || f[1] > 31 || f[2] > 23 || f[3] > 59 {
+		return t, ErrTimestamp
 	}
-	return deg, min, hems[0]
+
+	now = now.In(time.UTC)
+	t = time.Date(now.Year(), time.Month(f[0]), f[1], f[2], f[3], 0, 0, time.UTC)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+
+	return
+}
+
+// ambiguousCenter returns the center of the area covered by a latitude or
+// longitude with amb (1-4) trailing digits blanked: hundredths and tenths
+// of minutes, then minutes and tens of minutes.
+func ambiguousCenter(l float64, amb int) float64 {
+	units := [5]float64{0, 0.1, 1.0, 10.0, 60.0}
+	if amb <= 0 {
+		return l
+	}
+	unit := units[min(4, amb)]
+
+	// a small epsilon avoids truncating a value just below a boundary
+	// due to floating point error
+	m := math.Floor(math.Abs(l)*60.0/unit+1e-6)*unit + unit/2.0
+
+	return math.Copysign(m/60.0, l)
+}
+
+// blankDigits replaces the last amb digits of a rendered DDMM.hhN or
+// DDDMM.hhW coordinate with spaces.
+func blankDigits(s string, amb int) string {
+	b := []byte(s)
+	for _, i := range []int{len(b) - 2, len(b) - 3, len(b) - 5, len(b) - 6}[:min(4, max(0, amb))] {
+		b[i] = ' '
+	}
+
+	return string(b)
+}
+
+// dmsHundredths returns the absolute value of a latitude or longitude
+// as degrees and minutes to hundredths of a minute, with degLen degree
+// digits.  It's rounded before splitting the degrees so the minutes
+// can't round up to 60.
+func dmsHundredths(l float64, degLen int) string {
+	m := hundredthsMinutes(l)
+
+	return fmt.Sprintf("%0*d%02d.%02d", degLen, m/6000, m%6000/100, m%100)
+}
+
+// thousandthsMinutes returns the absolute value of a latitude or
+// longitude in thousandths of minutes.
+func thousandthsMinutes(l float64) int {
+	return int(math.Round(math.Abs(l) * 60000.0))
 }
@@ -6,6 +6,9 @@ package aprs
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ebarkie/weatherlink/units"
@@ -13,22 +16,45 @@ import (
 
 // Wx represents a weather station observation.
 type Wx struct {
-	Lat  float64
-	Lon  float64
-	Type string
+	Lat      float64
+	Lon      float64
+	Type     string
+	Software string // software name and version when parsed; String always renders SwName and SwVers
+
+	// Output format.  The default is an uncompressed positioned report.
+	Positionless bool // positionless report for stations whose position is beaconed separately
+	Compressed   bool // compressed position report with wind direction/speed in the cs bytes
+	Nonstandard  bool // also send the indoor and soil measurements; see below
 
 	Timestamp time.Time
 
 	Altimeter       float64
-	Humidity        int
+	Humidity        int // outdoor
 	RainLastHour    float64
 	RainLast24Hours float64
 	RainToday       float64
 	SolarRad        int
-	Temp            int
+	Temp            int // outdoor
 	WindDir         int
 	WindGust        int
 	WindSpeed       int
+
+	// Optional measurements which are only sent when set.
+	Battery    *float64 // volts
+	Radiation  *float64 // nanosieverts per hour
+	RainRaw    *int     // raw rain counter
+	Snowfall   *float64 // inches in the last 24 hours
+	WaterLevel *float64 // feet above or below flood stage
+
+	// Optional indoor and soil measurements.  These aren't part of the
+	// APRS specification so they're only sent, after all of the standard
+	// parameters, when Nonstandard is set.  They're only parsed from
+	// reports sent by this package, since other software may use the
+	// same letters for something else.
+	HumidityIndoor *int // percent
+	TempIndoor     *int // degrees Fahrenheit
+	SoilMoisture   *int // centibars
+	SoilTemp       *int // degrees Fahrenheit
 }
 
 // Zero zeroes all measurements in the observation payload.
@@ -45,6 +71,17 @@ func (w *Wx) Zero() {
 	w.WindDir = -1
 	w.WindGust = -1
 	w.WindSpeed = -1
+
+	w.Battery = nil
+	w.Radiation = nil
+	w.RainRaw = nil
+	w.Snowfall = nil
+	w.WaterLevel = nil
+
+	w.HumidityIndoor = nil
+	w.TempIndoor = nil
+	w.SoilMoisture = nil
+	w.SoilTemp = nil
 }
 
 // String returns an APRS packet for the provided measurements.
@@ -55,6 +92,9 @@ func (w Wx) String() (s string) {
 	// Parameters:
 	//   _ = wind direction (in degrees) [3 chars]
 	//   / = sustained one-minute wind speed (in mph) [3 chars]
+	//   (compressed reports carry both in the cs bytes instead, and c and
+	//   s are used in place of _ and / for positionless reports and for
+	//   compressed reports without a complete wind measurement)
 	//   g = gust (peak wind speed in mph in the last 5 minutes) [3 chars]
 	//   t = temperature (in degrees Fahrenheit). Temperatures below zero
 	//       are expressed as -01 to -99 [3 chars]
@@ -68,33 +108,72 @@ func (w Wx) String() (s string) {
 	//   L = luminosity (in watts per square meter) 999 and below [3 chars]
 	//   l = luminosity (in watts per square meter) 1000 and above [3 chars]
 	//   (L is inserted in place of one of the rain values)
-	//   s = snowfall (in inches) in the last 24 hours
-	//   # = raw rain counter
+	//   s = snowfall (in inches) in the last 24 hours [3 chars]
+	//   # = raw rain counter [3 chars]
+	//   F = water height (in feet above or below flood stage) [4 chars]
+	//   V = battery voltage (in tenths of volts) [3 chars]
+	//   X = radiation (in nanosieverts per hour) as a two digit mantissa
+	//       and one digit exponent [3 chars]
+	//
+	// Nonstandard extensions, only when enabled and always last:
+	//   i = indoor temperature (in degrees Fahrenheit) [3 chars]
+	//   H = indoor humidity (in %. 00 = 100%) [2 chars]
+	//   S = soil temperature (in degrees Fahrenheit) [3 chars]
+	//   m = soil moisture (in centibars) [3 chars]
 
 	// Timestamp as UTC two digit day, hour, and minute.
 	if w.Timestamp.IsZero() {
 		w.Timestamp = time.Now()
 	}
 
-	// Base prefix
-	latDeg, latMin, latHem := decToDMS(w.Lat, [2]string{"N", "S"})
-	lonDeg, lonMin, lonHem := decToDMS(w.Lon, [2]string{"E", "W"})
-	s = fmt.Sprintf("@%sz%02.0f%05.2f%s/%03.0f%05.2f%s",
-		w.Timestamp.In(time.UTC).Format("021504"),
-		latDeg, latMin, latHem,
-		lonDeg, lonMin, lonHem)
+	// Base prefix and wind direction/speed
+	ts := w.Timestamp.In(time.UTC)
+	dirKey, speedKey := "_", "/"
+	windParams := true
+	switch {
+	case w.Positionless:
+		s = "_" + ts.Format("01021504")
+		dirKey, speedKey = "c", "s"
+	case w.Compressed:
+		// Wind direction and speed are carried in the cs bytes
+		// rather than parameters.  The cs bytes can't mark just one of
+		// them as missing, so an incomplete measurement is sent as
+		// parameters with the "no course/speed" cs placeholder.
+		cs := csNone()
+		if w.WindDir >= 0 && w.WindSpeed >= 0 {
+			cs = csCourseSpeed(w.WindDir, int(math.Round(units.Speed(float64(w.WindSpeed)).Knots())))
+			windParams = false
+		} else {
+			dirKey, speedKey = "c", "s"
+		}
+		s = "@" + ts.Format("021504") + "z" + renderCompressed(w.Lat, w.Lon, "/_", cs)
+	default:
+		latHem, lonHem := "N", "E"
+		if w.Lat < 0 {
+			latHem = "S"
+		}
+		if w.Lon < 0 {
+			lonHem = "W"
+		}
+		s = fmt.Sprintf("@%sz%s%s/%s%s",
+			ts.Format("021504"),
+			dmsHundredths(w.Lat, 2), latHem,
+			dmsHundredths(w.Lon, 3), lonHem)
+	}
 
 	// Parameters
-	if w.WindDir < 0 {
-		s += "_..."
-	} else {
-		s += fmt.Sprintf("_%03d", w.WindDir)
-	}
+	if windParams {
+		if w.WindDir < 0 {
+			s += dirKey + "..."
+		} else {
+			s += fmt.Sprintf("%s%03d", dirKey, w.WindDir)
+		}
 
-	if w.WindSpeed < 0 {
-		s += "/..."
-	} else {
-		s += fmt.Sprintf("/%03d", w.WindSpeed)
+		if w.WindSpeed < 0 {
+			s += speedKey + "..."
+		} else {
+			s += fmt.Sprintf("%s%03d", speedKey, w.WindSpeed)
+		}
 	}
 
 	if w.WindGust < 0 {
@@ -145,6 +224,54 @@ func (w Wx) String() (s string) {
 		s += fmt.Sprintf("L%03d", w.SolarRad)
 	}
 
+	// s is wind speed when it's sent as c and s so snowfall can't be
+	// sent.
+	if w.Snowfall != nil && speedKey != "s" {
+		if *w.Snowfall < 9.95 {
+			s += fmt.Sprintf("s%.1f", *w.Snowfall)
+		} else {
+			s += fmt.Sprintf("s%03.0f", *w.Snowfall)
+		}
+	}
+
+	if w.RainRaw != nil {
+		s += fmt.Sprintf("#%03d", *w.RainRaw%1000)
+	}
+
+	if w.WaterLevel != nil {
+		if math.Abs(*w.WaterLevel) < 9.95 {
+			s += fmt.Sprintf("F%04.1f", *w.WaterLevel)
+		} else {
+			s += fmt.Sprintf("F%04.0f", *w.WaterLevel)
+		}
+	}
+
+	if w.Battery != nil {
+		s += fmt.Sprintf("V%03.0f", *w.Battery*10.0)
+	}
+
+	if w.Radiation != nil {
+		s += "X" + renderWxRadiation(*w.Radiation)
+	}
+
+	if w.Nonstandard {
+		if w.TempIndoor != nil {
+			s += fmt.Sprintf("i%03d", *w.TempIndoor)
+		}
+
+		if w.HumidityIndoor != nil {
+			s += fmt.Sprintf("H%02d", *w.HumidityIndoor%100)
+		}
+
+		if w.SoilTemp != nil {
+			s += fmt.Sprintf("S%03d", *w.SoilTemp)
+		}
+
+		if w.SoilMoisture != nil {
+			s += fmt.Sprintf("m%03d", *w.SoilMoisture)
+		}
+	}
+
 	// Software
 	s += SwName + SwVers
 	if w.Type != "" {
@@ -153,3 +280,274 @@ func (w Wx) String() (s string) {
 
 	return
 }
+
+// FromString sets the observation from an APRS weather report.  Both
+// positioned (uncompressed or compressed) and positionless reports are
+// supported.  Missing measurements are set the same as Zero.
+func (w *Wx) FromString(s string) (err error) {
+	*w = Wx{}
+	w.Zero()
+
+	if len(s) < 1 {
+		return ErrWxInvalid
+	}
+
+	// c and s are wind direction and speed in positionless reports
+	// and compressed reports without wind in the cs bytes.
+	windKeys := s[0] == '_'
+	w.Positionless = windKeys
+	if w.Positionless {
+		// _MMDDHHMM
+		if len(s) < 9 {
+			return ErrWxInvalid
+		}
+		if w.Timestamp, err = parseTimestampMDHM(s[1:9], time.Now()); err != nil {
+			return
+		}
+		s = s[9:]
+	} else {
+		p := PositionReport{}
+		if err = p.FromString(s); err != nil {
+			return
+		}
+		w.Lat, w.Lon, w.Timestamp = p.Lat, p.Lon, p.Timestamp
+		w.Compressed = p.Compressed
+
+		// Wind direction and speed are in the data extension, or the
+		// cs bytes for compressed reports.
+		if len(p.Extn) >= 7 && p.Extn[3] == '/' {
+			w.WindDir, _ = parseWxValue(p.Extn[0:3], -1)
+			w.WindSpeed, _ = parseWxValue(p.Extn[4:7], -1)
+			if p.Compressed && w.WindSpeed >= 0 {
+				w.WindSpeed = int(math.Round(float64(w.WindSpeed) / units.Speed(1).Knots()))
+			}
+		} else if p.Compressed {
+			windKeys = true
+		}
+		s = p.Comment
+	}
+
+	s = w.parseParams(s, windKeys)
+	s = w.parseNonstandard(s)
+
+	// Software and type
+	w.Software, w.Type, _ = strings.Cut(s, "-")
+
+	return
+}
+
+// parseParams sets the measurements from the weather parameters and
+// returns the remaining text.  If windKeys is set then s is the wind
+// speed rather than snowfall.
+func (w *Wx) parseParams(s string, windKeys bool) string {
+	for len(s) > 0 {
+		key := s[0]
+		width := 3
+		switch key {
+		case 'c', 's', 'g', 't', 'r', 'p', 'P', 'L', 'l', '#', 'V', 'X':
+		case 'h':
+			width = 2
+		case 'F':
+			width = 4
+		case 'b':
+			width = 5
+		default:
+			return s
+		}
+		if len(s) < 1+width || !validWxValue(s[1:1+width]) {
+			return s
+		}
+		v := s[1 : 1+width]
+		s = s[1+width:]
+
+		switch key {
+		case 'c':
+			w.WindDir, _ = parseWxValue(v, -1)
+		case 's':
+			// s is either wind speed or snowfall.
+			if windKeys {
+				w.WindSpeed, _ = parseWxValue(v, -1)
+			} else if f, ok := parseWxFloat(v); ok {
+				w.Snowfall = &f
+			}
+		case 'g':
+			w.WindGust, _ = parseWxValue(v, -1)
+		case 't':
+			w.Temp, _ = parseWxValue(v, -100)
+		case 'r':
+			w.RainLastHour = parseWxRain(v)
+		case 'p':
+			w.RainLast24Hours = parseWxRain(v)
+		case 'P':
+			w.RainToday = parseWxRain(v)
+		case 'h':
+			if h, ok := parseWxHumidity(v); ok {
+				w.Humidity = h
+			}
+		case 'b':
+			if b, ok := parseWxValue(v, 0); ok {
+				w.Altimeter = float64(b) / 10.0 / units.Pressure(1.0*units.Inches).Millibars()
+			}
+		case 'L':
+			w.SolarRad, _ = parseWxValue(v, -1)
+		case 'l':
+			if l, ok := parseWxValue(v, -1); ok {
+				w.SolarRad = l + 1000
+			}
+		case '#':
+			if r, ok := parseWxValue(v, -1); ok {
+				w.RainRaw = &r
+			}
+		case 'F':
+			if f, ok := parseWxFloat(v); ok {
+				w.WaterLevel = &f
+			}
+		case 'V':
+			if f, ok := parseWxFloat(v); ok {
+				f /= 10.0
+				w.Battery = &f
+			}
+		case 'X':
+			if f, ok := parseWxRadiation(v); ok {
+				w.Radiation = &f
+			}
+		}
+	}
+
+	return s
+}
+
+// parseNonstandard sets the indoor and soil measurements from the
+// nonstandard parameters and returns the remaining text.  They're only
+// parsed if they're followed by this package's software name, otherwise
+// s is returned unmodified.
+func (w *Wx) parseNonstandard(s string) string {
+	var params []string
+	t := s
+	for len(t) > 0 && strings.IndexByte("iHSm", t[0]) >= 0 {
+		width := 3
+		if t[0] == 'H' {
+			width = 2
+		}
+		if len(t) < 1+width || !validWxValue(t[1:1+width]) {
+			break
+		}
+		params = append(params, t[:1+width])
+		t = t[1+width:]
+	}
+	if len(params) == 0 || !strings.HasPrefix(t, SwName) {
+		return s
+	}
+
+	w.Nonstandard = true
+	for _, p := range params {
+		v := p[1:]
+		switch p[0] {
+		case 'i':
+			if t, ok := parseWxValue(v, -100); ok {
+				w.TempIndoor = &t
+			}
+		case 'H':
+			if h, ok := parseWxHumidity(v); ok {
+				w.HumidityIndoor = &h
+			}
+		case 'S':
+			if t, ok := parseWxValue(v, -100); ok {
+				w.SoilTemp = &t
+			}
+		case 'm':
+			if m, ok := parseWxValue(v, -1); ok {
+				w.SoilMoisture = &m
+			}
+		}
+	}
+
+	return t
+}
+
+// validWxValue returns true if v is a weather parameter value: either
+// numeric or missing (dots or spaces).
+func validWxValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if !(c >= '0' && c <= '9' || c == '.' || c == ' ' || c == '-' && i == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseWxValue returns the integer weather parameter value or, if it's
+// missing, the provided sentinel value.
+func parseWxValue(v string, missing int) (int, bool) {
+	f, ok := parseWxFloat(v)
+	if !ok {
+		return missing, false
+	}
+
+	return int(math.Round(f)), true
+}
+
+// parseWxHumidity returns the humidity in percent, where 00 is 100%,
+// and true or, if it's missing, false.
+func parseWxHumidity(v string) (int, bool) {
+	h, ok := parseWxValue(v, -1)
+	if ok && h == 0 {
+		h = 100
+	}
+
+	return h, ok
+}
+
+// parseWxFloat returns the weather parameter value and true or, if it's
+// missing, false.
+func parseWxFloat(v string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
+
+// renderWxRadiation returns the radiation in nanosieverts per hour as a
+// two digit mantissa followed by a one digit power of ten exponent.
+func renderWxRadiation(r float64) string {
+	m, e := math.Round(r), 0
+	for m >= 100 && e < 9 {
+		e++
+		m = math.Round(r / math.Pow10(e))
+	}
+	if m > 99 {
+		m = 99
+	}
+
+	return fmt.Sprintf("%02.0f%d", m, e)
+}
+
+// parseWxRadiation returns the radiation in nanosieverts per hour from
+// the mantissa and exponent parameter value.
+func parseWxRadiation(v string) (float64, bool) {
+	m, err := strconv.Atoi(v[0:2])
+	if err != nil {
+		return 0, false
+	}
+	e, err := strconv.Atoi(v[2:3])
+	if err != nil {
+		return 0, false
+	}
+
+	return float64(m) * math.Pow10(e), true
+}
+
+// parseWxRain returns the rainfall in inches from the hundredths of an
+// inch parameter value or, if it's missing, -1.
+func parseWxRain(v string) float64 {
+	r, ok := parseWxValue(v, -1)
+	if !ok {
+		return -1.0
+	}
+
+	return float64(r) / 100.0
+}
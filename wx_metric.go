@@ -0,0 +1,200 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"math"
+
+	"github.com/ebarkie/weatherlink/units"
+)
+
+// WxMetric represents weather station measurements in metric units.
+// Missing floating point measurements are NaN and missing integer
+// measurements are -1, the same as Zero.
+type WxMetric struct {
+	Altimeter       float64 // hectopascals
+	Humidity        int     // percent
+	RainLastHour    float64 // millimeters
+	RainLast24Hours float64 // millimeters
+	RainToday       float64 // millimeters
+	SolarRad        int     // watts per square meter
+	Temp            float64 // degrees Celsius
+	WindDir         int     // degrees
+	WindGust        float64 // meters per second
+	WindSpeed       float64 // meters per second
+
+	Snowfall   float64 // millimeters in the last 24 hours
+	WaterLevel float64 // meters above or below flood stage
+
+	HumidityIndoor int     // percent
+	TempIndoor     float64 // degrees Celsius
+	SoilMoisture   int     // centibars
+	SoilTemp       float64 // degrees Celsius
+}
+
+// Zero marks all measurements as missing.
+func (m *WxMetric) Zero() {
+	nan := math.NaN()
+
+	m.Altimeter = nan
+	m.Humidity = -1
+	m.RainLastHour = nan
+	m.RainLast24Hours = nan
+	m.RainToday = nan
+	m.SolarRad = -1
+	m.Temp = nan
+	m.WindDir = -1
+	m.WindGust = nan
+	m.WindSpeed = nan
+
+	m.Snowfall = nan
+	m.WaterLevel = nan
+
+	m.HumidityIndoor = -1
+	m.TempIndoor = nan
+	m.SoilMoisture = -1
+	m.SoilTemp = nan
+}
+
+// FromMetric sets the observation measurements from metric units.  Values
+// are rounded to the resolution of the APRS report so they survive a
+// String and FromString round trip.  The position, timestamp, and other
+// fields are left unchanged.
+func (w *Wx) FromMetric(m WxMetric) {
+	w.Altimeter, w.Humidity, w.SolarRad, w.WindDir = 0, m.Humidity, m.SolarRad, m.WindDir
+	if !math.IsNaN(m.Altimeter) {
+		w.Altimeter = m.Altimeter / units.Pressure(1.0*units.Inches).Millibars()
+	}
+
+	w.RainLastHour = metricToWx(m.RainLastHour, mmToInches, 2, -1.0)
+	w.RainLast24Hours = metricToWx(m.RainLast24Hours, mmToInches, 2, -1.0)
+	w.RainToday = metricToWx(m.RainToday, mmToInches, 2, -1.0)
+	w.Temp = int(metricToWx(m.Temp, celsiusToF, 0, -100))
+	w.WindGust = int(metricToWx(m.WindGust, mpsToMPH, 0, -1))
+	w.WindSpeed = int(metricToWx(m.WindSpeed, mpsToMPH, 0, -1))
+
+	w.Snowfall = metricToWxPtr(m.Snowfall, mmToInches, 1)
+	w.WaterLevel = metricToWxPtr(m.WaterLevel, func(v float64) float64 { return units.Length(v * units.Meters).Feet() }, 1)
+
+	w.HumidityIndoor, w.SoilMoisture = nil, nil
+	if m.HumidityIndoor >= 0 {
+		w.HumidityIndoor = &m.HumidityIndoor
+	}
+	if m.SoilMoisture >= 0 {
+		w.SoilMoisture = &m.SoilMoisture
+	}
+	w.TempIndoor = metricToWxIntPtr(m.TempIndoor, celsiusToF)
+	w.SoilTemp = metricToWxIntPtr(m.SoilTemp, celsiusToF)
+}
+
+// Metric returns the observation measurements in metric units.  Values
+// are rounded to a resolution that converts back to the same imperial
+// values.
+func (w Wx) Metric() (m WxMetric) {
+	m.Zero()
+
+	m.Humidity, m.SolarRad, m.WindDir = w.Humidity, w.SolarRad, w.WindDir
+	if w.Altimeter > 0.0 {
+		m.Altimeter = round(units.Pressure(w.Altimeter*units.Inches).Hectopascals(), 1)
+	}
+
+	if w.RainLastHour >= 0.0 {
+		m.RainLastHour = round(units.Length(w.RainLastHour).Millimeters(), 1)
+	}
+	if w.RainLast24Hours >= 0.0 {
+		m.RainLast24Hours = round(units.Length(w.RainLast24Hours).Millimeters(), 1)
+	}
+	if w.RainToday >= 0.0 {
+		m.RainToday = round(units.Length(w.RainToday).Millimeters(), 1)
+	}
+	if w.Temp >= -99 {
+		m.Temp = round(units.Fahrenheit(float64(w.Temp)).Celsius(), 1)
+	}
+	if w.WindGust >= 0 {
+		m.WindGust = round(units.Speed(float64(w.WindGust)).MPS(), 1)
+	}
+	if w.WindSpeed >= 0 {
+		m.WindSpeed = round(units.Speed(float64(w.WindSpeed)).MPS(), 1)
+	}
+
+	if w.Snowfall != nil {
+		m.Snowfall = round(units.Length(*w.Snowfall).Millimeters(), 1)
+	}
+	if w.WaterLevel != nil {
+		m.WaterLevel = round(units.Length(*w.WaterLevel*12.0).Meters(), 2)
+	}
+
+	if w.HumidityIndoor != nil {
+		m.HumidityIndoor = *w.HumidityIndoor
+	}
+	if w.TempIndoor != nil {
+		m.TempIndoor = round(units.Fahrenheit(float64(*w.TempIndoor)).Celsius(), 1)
+	}
+	if w.SoilMoisture != nil {
+		m.SoilMoisture = *w.SoilMoisture
+	}
+	if w.SoilTemp != nil {
+		m.SoilTemp = round(units.Fahrenheit(float64(*w.SoilTemp)).Celsius(), 1)
+	}
+
+	return
+}
+
+// metricToWx converts a metric measurement using the conversion function
+// and rounds it to places.  If the measurement is missing (NaN) the
+// provided sentinel value is returned.
+func metricToWx(v float64, conv func(float64) float64, places int, missing float64) float64 {
+	if math.IsNaN(v) {
+		return missing
+	}
+
+	return round(conv(v), places)
+}
+
+// metricToWxPtr converts an optional metric measurement using the
+// conversion function and rounds it to places.  If the measurement is
+// missing (NaN) nil is returned.
+func metricToWxPtr(v float64, conv func(float64) float64, places int) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	r := round(conv(v), places)
+
+	return &r
+}
+
+// metricToWxIntPtr converts an optional metric measurement using the
+// conversion function and rounds it to a whole number.  If the
+// measurement is missing (NaN) nil is returned.
+func metricToWxIntPtr(v float64, conv func(float64) float64) *int {
+	if math.IsNaN(v) {
+		return nil
+	}
+	r := int(round(conv(v), 0))
+
+	return &r
+}
+
+// celsiusToF converts degrees Celsius to Fahrenheit.
+func celsiusToF(c float64) float64 {
+	return units.Celsius(c).Fahrenheit()
+}
+
+// mmToInches converts millimeters to inches.
+func mmToInches(mm float64) float64 {
+	return mm / 1000.0 * units.Meters
+}
+
+// mpsToMPH converts meters per second to miles per hour.
+func mpsToMPH(mps float64) float64 {
+	return mps / units.Speed(1.0*units.MPH).MPS()
+}
+
+// round returns v rounded to the number of decimal places.
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+
+	return math.Round(v*p) / p
+}
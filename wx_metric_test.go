@@ -0,0 +1,93 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleWx_FromMetric() {
+	m := WxMetric{}
+	m.Zero()
+	m.Altimeter = 1011.5
+	m.Temp = 22.2
+	m.WindDir = 180
+	m.WindSpeed = 3.6
+	m.RainToday = 5.3
+
+	w := Wx{}
+	w.Zero()
+	w.FromMetric(m)
+	fmt.Printf("%.2f inHg %d F %d mph %.2f in\n", w.Altimeter, w.Temp, w.WindSpeed, w.RainToday)
+
+	// Output:
+	// 29.87 inHg 72 F 8 mph 0.21 in
+}
+
+func TestWxMetric(t *testing.T) {
+	a := assert.New(t)
+
+	m := WxMetric{}
+	m.Zero()
+	w := Wx{}
+	w.Zero()
+	w.FromMetric(m)
+	want := Wx{}
+	want.Zero()
+	a.Equal(want, w, "Missing measurements")
+	got := w.Metric()
+	a.True(math.IsNaN(got.Temp), "Missing temperature")
+	a.Equal(-1, got.Humidity, "Missing humidity")
+
+	m.Altimeter = 1013.2
+	m.Humidity = 61
+	m.RainLastHour = 1.3
+	m.RainLast24Hours = 25.4
+	m.RainToday = 0.0
+	m.SolarRad = 812
+	m.Temp = -7.3
+	m.WindDir = 270
+	m.WindGust = 12.4
+	m.WindSpeed = 5.2
+	m.Snowfall = 76.2
+	m.WaterLevel = -0.75
+	m.HumidityIndoor = 40
+	m.TempIndoor = 21.5
+	m.SoilMoisture = 25
+	m.SoilTemp = 12.0
+	w.FromMetric(m)
+	a.InDelta(29.92, w.Altimeter, 0.01, "Altimeter")
+	a.Equal(0.05, w.RainLastHour, "Rain last hour")
+	a.Equal(1.0, w.RainLast24Hours, "Rain last 24 hours")
+	a.Equal(0.0, w.RainToday, "Rain today")
+	a.Equal(19, w.Temp, "Temperature")
+	a.Equal(28, w.WindGust, "Wind gust")
+	a.Equal(12, w.WindSpeed, "Wind speed")
+	a.Equal(ptr(3.0), w.Snowfall, "Snowfall")
+	a.Equal(ptr(-2.5), w.WaterLevel, "Water level")
+	a.Equal(ptr(40), w.HumidityIndoor, "Indoor humidity")
+	a.Equal(ptr(71), w.TempIndoor, "Indoor temperature")
+	a.Equal(ptr(25), w.SoilMoisture, "Soil moisture")
+	a.Equal(ptr(54), w.SoilTemp, "Soil temperature")
+
+	// Imperial to metric and back must be lossless.
+	w.Timestamp = testWx.Timestamp
+	got = w.Metric()
+	a.Equal(1013.2, got.Altimeter, "Metric altimeter")
+	a.Equal(-7.2, got.Temp, "Metric temperature")
+	back := w
+	back.FromMetric(got)
+	a.Equal(w.String(), back.String(), "Metric round trip")
+
+	// Parsed reports can be read back in metric.
+	w.Nonstandard = true
+	p := Wx{}
+	a.Nil(p.FromString(w.String()), "Valid weather")
+	a.Equal(got, p.Metric(), "Parsed metric")
+}
@@ -6,7 +6,10 @@ package aprs
 
 import (
 	"fmt"
+	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var testWx = Wx{
@@ -15,6 +18,11 @@ var testWx = Wx{
 	Type: "Stn",
 }
 
+// ptr returns a pointer to v for setting optional measurements.
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func init() {
 	testWx.Zero()
 	testWx.Timestamp = time.Date(2016, time.November, 5, 20, 35, 0, 0, time.UTC)
@@ -124,3 +132,223 @@ func ExampleWx_String_wind() {
 	// @052035z3542.00N/07842.00W_000/000g000t...r...p...P...h..b.....GoTst9-Stn
 	// @052035z3542.00N/07842.00W_180/008g016t...r...p...P...h..b.....GoTst9-Stn
 }
+
+func ExampleWx_String_extended() {
+	w := testWx
+
+	w.Snowfall = ptr(0.5)
+	w.RainRaw = ptr(123)
+	w.WaterLevel = ptr(-2.5)
+	w.Battery = ptr(13.8)
+	w.Radiation = ptr(1200.0)
+	fmt.Println(w)
+
+	w.Snowfall = ptr(12.0)
+	w.WaterLevel = ptr(14.0)
+	fmt.Println(w)
+
+	w = testWx
+	w.Temp = 28
+	w.Humidity = 95
+	w.TempIndoor = ptr(68)
+	w.HumidityIndoor = ptr(40)
+	w.SoilTemp = ptr(-3)
+	w.SoilMoisture = ptr(25)
+	fmt.Println(w)
+
+	w.Nonstandard = true
+	fmt.Println(w)
+
+	// Output:
+	// @052035z3542.00N/07842.00W_.../...g...t...r...p...P...h..b.....s0.5#123F-2.5V138X122GoTst9-Stn
+	// @052035z3542.00N/07842.00W_.../...g...t...r...p...P...h..b.....s012#123F0014V138X122GoTst9-Stn
+	// @052035z3542.00N/07842.00W_.../...g...t028r...p...P...h95b.....GoTst9-Stn
+	// @052035z3542.00N/07842.00W_.../...g...t028r...p...P...h95b.....i068H40S-03m025GoTst9-Stn
+}
+
+func TestWxZeroValue(t *testing.T) {
+	// A zero value Wx, without Zero, mustn't send optional measurements.
+	w := Wx{Lat: 35.7, Lon: -78.7, Timestamp: testWx.Timestamp}
+	assert.Equal(t, "@052035z3542.00N/07842.00W_000/000g000t000r000p000P000h00b.....L000"+SwName+SwVers, w.String(), "Zero value")
+}
+
+func ExampleWx_String_positionless() {
+	w := testWx
+	w.Positionless = true
+
+	w.WindDir = 180
+	w.WindSpeed = 8
+	w.Temp = 72
+	fmt.Println(w)
+
+	// Output:
+	// _11052035c180s008g...t072r...p...P...h..b.....GoTst9-Stn
+}
+
+func ExampleWx_String_compressed() {
+	w := testWx
+	w.Compressed = true
+	fmt.Println(w)
+
+	w.WindDir = 88
+	w.WindSpeed = 42
+	w.Temp = 72
+	fmt.Println(w)
+
+	// Output:
+	// @052035z/<Iii:Wrr_  #c...s...g...t...r...p...P...h..b.....GoTst9-Stn
+	// @052035z/<Iii:Wrr_7P[g...t072r...p...P...h..b.....GoTst9-Stn
+}
+
+func TestWxFromString(t *testing.T) {
+	a := assert.New(t)
+
+	want := Wx{}
+	want.Zero()
+	want.WindDir = 220
+	want.WindSpeed = 4
+	want.WindGust = 5
+	want.Temp = 77
+	want.RainLastHour = 0.0
+	want.RainLast24Hours = 0.0
+	want.RainToday = 0.0
+	want.Humidity = 50
+	want.Software = "wRSW"
+
+	for _, s := range []string{
+		"_10090556c220s004g005t077r000p000P000h50b09900wRSW",
+		"!4903.50N/07201.75W_220/004g005t077r000p000P000h50b09900wRSW",
+		"@092345z4903.50N/07201.75W_220/004g005t077r000p000P000h50b09900wRSW",
+	} {
+		w := Wx{}
+		a.Nil(w.FromString(s), "Valid weather %s", s)
+		a.InDelta(29.23, w.Altimeter, 0.01, "Altimeter %s", s)
+		if s[0] != '_' {
+			a.InDelta(49.058333, w.Lat, 0.00001, "Lat %s", s)
+			a.InDelta(-72.029167, w.Lon, 0.00001, "Lon %s", s)
+		}
+		a.Equal(s[0] == '_', w.Positionless, "Positionless %s", s)
+		w.Altimeter, w.Lat, w.Lon, w.Timestamp, w.Positionless = 0, 0, 0, time.Time{}, false
+		a.Equal(want, w, "Weather %s", s)
+	}
+
+	w := Wx{}
+	a.Nil(w.FromString("=/5L!!<*e7_7P[g005t077r000p000P000h50b09900wRSW"), "Valid compressed weather")
+	a.Equal(88, w.WindDir, "Compressed wind direction")
+	a.Equal(41, w.WindSpeed, "Compressed wind speed")
+	a.Equal(5, w.WindGust, "Compressed wind gust")
+	a.True(w.Compressed, "Compressed")
+
+	a.Nil(w.FromString("=3438.51N/07941.15W_120/001g004t073r   p   P000h  b     KU2k"), "Valid weather with spaces")
+	a.Equal(-1.0, w.RainLastHour, "Missing rain")
+	a.Equal(-1, w.Humidity, "Missing humidity")
+	a.Equal(0.0, w.Altimeter, "Missing altimeter")
+	a.Equal("KU2k", w.Software, "Software")
+
+	a.Nil(w.FromString("_10090556c...s...g...t-05L123l...#123s.5 Go3-Stn"), "Valid weather with luminosity")
+	a.Equal(-5, w.Temp, "Negative temperature")
+	a.Equal(123, w.SolarRad, "Luminosity")
+	a.Equal("Go3", w.Software, "Software")
+	a.Equal("Stn", w.Type, "Type")
+
+	a.Nil(w.FromString("!4903.50N/07201.75W_220/004g005t077s1.5#045F12.5V126X253wRSW"), "Valid extended weather")
+	a.Equal(ptr(1.5), w.Snowfall, "Snowfall")
+	a.Equal(ptr(45), w.RainRaw, "Raw rain counter")
+	a.Equal(ptr(12.5), w.WaterLevel, "Water level")
+	a.InDelta(12.6, *w.Battery, 0.001, "Battery")
+	a.Equal(ptr(25000.0), w.Radiation, "Radiation")
+	a.Nil(w.TempIndoor, "Missing indoor temperature")
+	a.Equal("wRSW", w.Software, "Software")
+
+	a.Nil(w.FromString("!4903.50N/07201.75W_220/004g005t077h50i071H00S-10m..."+SwName+SwVers), "Valid indoor and soil weather")
+	a.True(w.Nonstandard, "Nonstandard")
+	a.Equal(ptr(71), w.TempIndoor, "Indoor temperature")
+	a.Equal(ptr(100), w.HumidityIndoor, "Indoor humidity")
+	a.Equal(ptr(-10), w.SoilTemp, "Soil temperature")
+	a.Nil(w.SoilMoisture, "Missing soil moisture")
+	a.Equal(SwName+SwVers, w.Software, "Software")
+
+	a.Nil(w.FromString("!4903.50N/07201.75W_220/004g005t077h50i071H00wRSW"), "Valid weather from other software")
+	a.False(w.Nonstandard, "Not nonstandard")
+	a.Nil(w.TempIndoor, "Foreign indoor temperature")
+	a.Equal("i071H00wRSW", w.Software, "Software")
+
+	a.Nil(w.FromString("_10090556c...s...g...t077S123 Weather"), "Valid weather with comment")
+	a.Nil(w.SoilTemp, "Foreign soil temperature")
+	a.Equal("S123 Weather", w.Software, "Software")
+
+	for _, s := range []string{"", "_1009", "_13090556c220", "!4903.50N/07201.75"} {
+		a.NotNil(w.FromString(s), "Invalid weather %q", s)
+	}
+}
+
+func TestWxRoundTrip(t *testing.T) {
+	w := testWx
+	w.Altimeter = 29.87
+	w.Humidity = 100
+	w.RainLastHour = 0.54
+	w.RainLast24Hours = 0.23
+	w.RainToday = 0.21
+	w.SolarRad = 1864
+	w.Temp = -20
+	w.WindDir = 180
+	w.WindSpeed = 8
+	w.WindGust = 16
+	w.Snowfall = ptr(3.5)
+	w.RainRaw = ptr(999)
+	w.WaterLevel = ptr(-12.0)
+	w.Battery = ptr(12.1)
+	w.Radiation = ptr(37.0)
+	w.TempIndoor = ptr(70)
+	w.HumidityIndoor = ptr(35)
+	w.SoilTemp = ptr(55)
+	w.SoilMoisture = ptr(120)
+	w.Nonstandard = true
+
+	got := Wx{}
+	assert.Nil(t, got.FromString(w.String()), "Valid weather")
+	assert.Equal(t, w.String(), got.String(), "Round trip")
+	assert.Equal(t, w.Type, got.Type, "Type")
+	assert.Equal(t, w.SoilMoisture, got.SoilMoisture, "Soil moisture")
+
+	w.Positionless = true
+	assert.Nil(t, got.FromString(w.String()), "Valid positionless weather")
+	assert.Equal(t, w.String(), got.String(), "Positionless round trip")
+
+	w.Positionless = false
+	w.Compressed = true
+	assert.Nil(t, got.FromString(w.String()), "Valid compressed weather")
+	assert.Equal(t, w.String(), got.String(), "Compressed round trip")
+	assert.Equal(t, 180, got.WindDir, "Compressed wind direction")
+	assert.Equal(t, 8, got.WindSpeed, "Compressed wind speed")
+
+	w.WindSpeed = -1
+	assert.Nil(t, got.FromString(w.String()), "Valid compressed weather without wind speed")
+	assert.Equal(t, w.String(), got.String(), "Compressed round trip without wind speed")
+	assert.Equal(t, 180, got.WindDir, "Compressed wind direction")
+	assert.Equal(t, -1, got.WindSpeed, "Compressed missing wind speed")
+}
+
+func TestWxRoundTripWholeDegrees(t *testing.T) {
+	w := testWx
+	w.Lat, w.Lon = 45.99999, -78.99999
+
+	got := Wx{}
+	assert.Nil(t, got.FromString(w.String()), "Valid weather")
+	assert.Contains(t, w.String(), "4600.00N/07900.00W_", "Minutes rounded into degrees")
+	assert.Equal(t, 46.0, got.Lat, "Latitude")
+	assert.Equal(t, -79.0, got.Lon, "Longitude")
+}
+
+func TestWxDecode(t *testing.T) {
+	for _, s := range []string{
+		"N0CALL>APRS:_10090556c220s004g005t077r000p000P000h50b09900wRSW",
+		"N0CALL>APRS:!4903.50N/07201.75W_220/004g005t077r000p000P000h50b09900wRSW",
+	} {
+		f := Frame{}
+		f.FromString(s)
+		v, err := f.Decode()
+		assert.Nil(t, err, "Decode %s", s)
+		assert.IsType(t, Wx{}, v, "Decode %s", s)
+	}
+}
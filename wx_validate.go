@@ -0,0 +1,176 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Weather report plausibility limits.  Measurements outside of these
+// are almost certainly sensor or conversion faults.
+const (
+	WxMaxAge       = time.Hour       // oldest acceptable observation
+	WxMaxFuture    = 5 * time.Minute // allowed clock skew for observations in the future
+	WxMinAltimeter = 25.0            // inches of mercury
+	WxMaxAltimeter = 32.5            // inches of mercury
+	WxMinTemp      = -80             // degrees Fahrenheit
+	WxMaxTemp      = 140             // degrees Fahrenheit
+	WxMaxWind      = 200             // mph
+	WxMaxRainHour  = 10.0            // inches
+	WxMaxRainDay   = 50.0            // inches
+	WxMaxSnowDay   = 100.0           // inches
+	WxMaxWater     = 100.0           // feet above or below flood stage
+	WxMaxBattery   = 99.9            // volts
+	WxMaxRadiation = 99e9            // nanosieverts per hour
+	WxMaxSoilMoist = 239             // centibars
+)
+
+// WxProblem is a single weather report quality control failure.
+type WxProblem struct {
+	Field  string // Wx field name
+	Reason string
+}
+
+// String returns the field name followed by the reason.
+func (p WxProblem) String() string {
+	return p.Field + " " + p.Reason
+}
+
+// WxProblems is the list of quality control failures for a weather
+// report.  It satisfies errors.Is(err, ErrWxInvalid).
+type WxProblems []WxProblem
+
+// Error returns all of the problems as a single error string.
+func (ps WxProblems) Error() string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = p.String()
+	}
+
+	return ErrWxInvalid.Error() + ": " + strings.Join(s, ", ")
+}
+
+// Is reports whether target is ErrWxInvalid.
+func (ps WxProblems) Is(target error) bool {
+	return target == ErrWxInvalid
+}
+
+// Has returns true if there is a problem with the named field.
+func (ps WxProblems) Has(field string) bool {
+	for _, p := range ps {
+		if p.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate performs quality control checks on the observation and
+// returns WxProblems listing every failure, or nil if it looks sane.
+// Missing measurements are not problems.
+func (w Wx) Validate() error {
+	return w.validate(time.Now())
+}
+
+func (w Wx) validate(now time.Time) error {
+	var ps WxProblems
+	add := func(field, format string, a ...any) {
+		ps = append(ps, WxProblem{Field: field, Reason: fmt.Sprintf(format, a...)})
+	}
+
+	// A zero timestamp is filled in with the current time by String.
+	if !w.Timestamp.IsZero() {
+		if age := now.Sub(w.Timestamp); age > WxMaxAge {
+			add("Timestamp", "is stale (%s old)", age.Round(time.Second))
+		} else if -age > WxMaxFuture {
+			add("Timestamp", "is in the future (%s ahead)", (-age).Round(time.Second))
+		}
+	}
+
+	if w.Altimeter > 0.0 && (w.Altimeter < WxMinAltimeter || w.Altimeter > WxMaxAltimeter) {
+		add("Altimeter", "%.2f outside %.1f-%.1f inHg", w.Altimeter, WxMinAltimeter, WxMaxAltimeter)
+	}
+
+	if w.Humidity > 100 || w.Humidity == 0 {
+		add("Humidity", "%d outside 1-100%%", w.Humidity)
+	}
+	if w.HumidityIndoor != nil && (*w.HumidityIndoor < 1 || *w.HumidityIndoor > 100) {
+		add("HumidityIndoor", "%d outside 1-100%%", *w.HumidityIndoor)
+	}
+
+	if w.Temp >= -99 && (w.Temp < WxMinTemp || w.Temp > WxMaxTemp) {
+		add("Temp", "%d outside %d-%d F", w.Temp, WxMinTemp, WxMaxTemp)
+	}
+	if w.TempIndoor != nil && (*w.TempIndoor < WxMinTemp || *w.TempIndoor > WxMaxTemp) {
+		add("TempIndoor", "%d outside %d-%d F", *w.TempIndoor, WxMinTemp, WxMaxTemp)
+	}
+	if w.SoilTemp != nil && (*w.SoilTemp < WxMinTemp || *w.SoilTemp > WxMaxTemp) {
+		add("SoilTemp", "%d outside %d-%d F", *w.SoilTemp, WxMinTemp, WxMaxTemp)
+	}
+	if w.SoilMoisture != nil && (*w.SoilMoisture < 0 || *w.SoilMoisture > WxMaxSoilMoist) {
+		add("SoilMoisture", "%d outside 0-%d cb", *w.SoilMoisture, WxMaxSoilMoist)
+	}
+
+	if w.WindDir > 360 {
+		add("WindDir", "%d outside 0-360 degrees", w.WindDir)
+	}
+	if w.WindSpeed > WxMaxWind {
+		add("WindSpeed", "%d above %d mph", w.WindSpeed, WxMaxWind)
+	}
+	if w.WindGust > WxMaxWind {
+		add("WindGust", "%d above %d mph", w.WindGust, WxMaxWind)
+	}
+	if w.WindGust >= 0 && w.WindSpeed >= 0 && w.WindGust < w.WindSpeed {
+		add("WindGust", "%d below sustained speed %d mph", w.WindGust, w.WindSpeed)
+	}
+
+	if w.RainLastHour > WxMaxRainHour {
+		add("RainLastHour", "%.2f above %.0f in", w.RainLastHour, WxMaxRainHour)
+	}
+	if w.RainLast24Hours > WxMaxRainDay {
+		add("RainLast24Hours", "%.2f above %.0f in", w.RainLast24Hours, WxMaxRainDay)
+	}
+	if w.RainToday > WxMaxRainDay {
+		add("RainToday", "%.2f above %.0f in", w.RainToday, WxMaxRainDay)
+	}
+	// The last hour and since midnight are both within the last 24
+	// hours so neither can exceed it.
+	if w.RainLast24Hours >= 0.0 {
+		if w.RainLastHour > w.RainLast24Hours {
+			add("RainLastHour", "%.2f above last 24 hours %.2f in", w.RainLastHour, w.RainLast24Hours)
+		}
+		if w.RainToday > w.RainLast24Hours {
+			add("RainToday", "%.2f above last 24 hours %.2f in", w.RainToday, w.RainLast24Hours)
+		}
+	}
+
+	if w.Snowfall != nil && (*w.Snowfall < 0.0 || *w.Snowfall > WxMaxSnowDay) {
+		add("Snowfall", "%.1f outside 0-%.0f in", *w.Snowfall, WxMaxSnowDay)
+	}
+	if w.WaterLevel != nil && math.Abs(*w.WaterLevel) > WxMaxWater {
+		add("WaterLevel", "%.1f outside +/-%.0f ft", *w.WaterLevel, WxMaxWater)
+	}
+
+	if w.SolarRad > 1999 {
+		add("SolarRad", "%d above 1999 W/m^2", w.SolarRad)
+	}
+	if w.Radiation != nil && (*w.Radiation < 0.0 || *w.Radiation > WxMaxRadiation) {
+		add("Radiation", "%.0f outside 0-%.0f nSv/h", *w.Radiation, WxMaxRadiation)
+	}
+
+	if w.Battery != nil && (*w.Battery < 0.0 || *w.Battery > WxMaxBattery) {
+		add("Battery", "%.1f outside 0-%.1f V", *w.Battery, WxMaxBattery)
+	}
+
+	if len(ps) > 0 {
+		return ps
+	}
+
+	return nil
+}
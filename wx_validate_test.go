@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWxValidate(t *testing.T) {
+	a := assert.New(t)
+
+	now := testWx.Timestamp.Add(time.Minute)
+
+	w := testWx
+	a.Nil(w.validate(now), "Missing measurements")
+
+	w.Altimeter = 29.92
+	w.Humidity = 100
+	w.RainLastHour = 0.5
+	w.RainLast24Hours = 1.25
+	w.RainToday = 1.0
+	w.SolarRad = 850
+	w.Temp = -20
+	w.WindDir = 360
+	w.WindSpeed = 12
+	w.WindGust = 18
+	w.Battery = ptr(12.6)
+	w.Radiation = ptr(120.0)
+	w.Snowfall = ptr(3.5)
+	w.WaterLevel = ptr(-2.0)
+	w.HumidityIndoor = ptr(40)
+	w.TempIndoor = ptr(68)
+	w.SoilMoisture = ptr(25)
+	w.SoilTemp = ptr(55)
+	a.Nil(w.validate(now), "Valid measurements")
+
+	w.Altimeter = 2.992
+	w.Humidity = 101
+	w.RainLastHour = 1.5
+	w.RainToday = 60.0
+	w.Temp = 212
+	w.WindGust = 8
+	w.Battery = ptr(-1.0)
+	w.Radiation = ptr(-5.0)
+	w.Snowfall = ptr(250.0)
+	w.WaterLevel = ptr(500.0)
+	w.HumidityIndoor = ptr(0)
+	w.TempIndoor = ptr(150)
+	w.SoilMoisture = ptr(300)
+	w.SoilTemp = ptr(-90)
+	err := w.validate(now.Add(2 * time.Hour))
+	a.True(errors.Is(err, ErrWxInvalid), "Is ErrWxInvalid")
+
+	var ps WxProblems
+	a.True(errors.As(err, &ps), "As WxProblems")
+	for _, f := range []string{"Timestamp", "Altimeter", "Humidity", "Temp", "WindGust", "RainLastHour", "RainToday",
+		"Battery", "Radiation", "Snowfall", "WaterLevel", "HumidityIndoor", "TempIndoor", "SoilMoisture", "SoilTemp"} {
+		a.True(ps.Has(f), "Problem with %s", f)
+	}
+	a.False(ps.Has("WindSpeed"), "No problem with WindSpeed")
+	a.Contains(err.Error(), "WindGust 8 below sustained speed 12 mph", "Error text")
+
+	w = testWx
+	err = w.validate(testWx.Timestamp.Add(-10 * time.Minute))
+	a.Equal(WxProblems{{"Timestamp", "is in the future (10m0s ahead)"}}, err, "Future timestamp")
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// WxAggregator defaults.
+const (
+	DefaultWxRainPerTip = 0.01 // inches
+	DefaultWxStale      = 5 * time.Minute
+)
+
+// Weather report windows.
+const (
+	wxWindSustained = time.Minute
+	wxWindGust      = 5 * time.Minute
+	wxRainHour      = time.Hour
+	wxRainDay       = 24 * time.Hour
+)
+
+// WxSample is a raw sensor reading.  Missing measurements are set the
+// same as Wx.Zero.
+type WxSample struct {
+	Timestamp time.Time
+
+	Altimeter float64 // inches of mercury
+	Humidity  int
+	RainCount int // cumulative rain gauge tip counter
+	SolarRad  int
+	Temp      int
+	WindDir   int
+	WindSpeed float64 // instantaneous mph
+}
+
+// Zero zeroes all measurements in the sample.
+func (s *WxSample) Zero() {
+	s.Timestamp = time.Time{}
+
+	s.Altimeter = 0
+	s.Humidity = -1
+	s.RainCount = -1
+	s.SolarRad = -1
+	s.Temp = -100
+	s.WindDir = -1
+	s.WindSpeed = -1.0
+}
+
+// WxAggregator turns raw sensor samples into the values CWOP expects in
+// a weather report: one-minute sustained wind, five-minute peak gust,
+// and rain in the last hour, last 24 hours, and since local midnight.
+// Other measurements are reported as the latest sample as long as it's
+// not older than Stale.
+type WxAggregator struct {
+	RainPerTip  float64          // inches per rain counter tip; defaults to DefaultWxRainPerTip
+	RainCounter int              // rain counter modulus, e.g. 65536, or 0 if unknown
+	Stale       time.Duration    // maximum age of instantaneous measurements; defaults to DefaultWxStale
+	Location    *time.Location   // time zone for the midnight rain reset; defaults to time.Local
+	Now         func() time.Time // clock; defaults to time.Now
+
+	mu      sync.Mutex
+	last    WxSample             // latest instantaneous measurements
+	seen    map[string]time.Time // when each measurement was last seen, by parameter
+	winds   []wxWind
+	rains   []wxRain
+	count   int     // last rain counter
+	counted bool    // count is set
+	total   float64 // accumulated rain in inches
+}
+
+// wxWind is an instantaneous wind sample.
+type wxWind struct {
+	t     time.Time
+	dir   int
+	speed float64
+}
+
+// wxRain is the accumulated rain, in inches, at a point in time.
+type wxRain struct {
+	t     time.Time
+	total float64
+}
+
+// Add adds a raw sensor sample.  Samples are expected in chronological
+// order.
+func (a *WxAggregator) Add(s WxSample) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if s.Timestamp.IsZero() {
+		s.Timestamp = a.now()
+	}
+	if a.seen == nil {
+		a.seen = map[string]time.Time{}
+	}
+
+	t := s.Timestamp
+	if s.Altimeter > 0.0 {
+		a.last.Altimeter, a.seen["b"] = s.Altimeter, t
+	}
+	if s.Humidity >= 0 {
+		a.last.Humidity, a.seen["h"] = s.Humidity, t
+	}
+	if s.SolarRad >= 0 {
+		a.last.SolarRad, a.seen["L"] = s.SolarRad, t
+	}
+	if s.Temp >= -99 {
+		a.last.Temp, a.seen["t"] = s.Temp, t
+	}
+
+	if s.WindSpeed >= 0.0 {
+		a.winds = append(a.winds, wxWind{t: t, dir: s.WindDir, speed: s.WindSpeed})
+		a.winds = pruneWx(a.winds, t.Add(-wxWindGust), func(w wxWind) time.Time { return w.t })
+	}
+
+	if s.RainCount >= 0 {
+		if a.counted {
+			d := s.RainCount - a.count
+			if d < 0 {
+				// The counter rolled over.  If its modulus is known
+				// then the tips can be recovered, otherwise it may
+				// have been reset to any value so start counting again
+				// from here.
+				d = 0
+				if a.RainCounter > 0 {
+					d = s.RainCount + a.RainCounter - a.count
+				}
+			}
+			a.total += float64(d) * a.rainPerTip()
+		}
+		a.count, a.counted = s.RainCount, true
+		a.rains = append(a.rains, wxRain{t: t, total: a.total})
+		a.rains = pruneWx(a.rains, t.Add(-wxRainDay), func(r wxRain) time.Time { return r.t })
+	}
+}
+
+// Wx returns a weather observation for the current time from the
+// samples.  Position and type are left for the caller to set.
+func (a *WxAggregator) Wx() (w Wx) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	w.Zero()
+	w.Timestamp = now
+
+	fresh := func(k string) bool {
+		t, ok := a.seen[k]
+		return ok && now.Sub(t) <= a.stale()
+	}
+	if fresh("b") {
+		w.Altimeter = a.last.Altimeter
+	}
+	if fresh("h") {
+		w.Humidity = a.last.Humidity
+	}
+	if fresh("L") {
+		w.SolarRad = a.last.SolarRad
+	}
+	if fresh("t") {
+		w.Temp = a.last.Temp
+	}
+
+	// Wind speed is the average over the sustained window and direction
+	// is the speed weighted vector average.  Gust is the peak over the
+	// gust window.
+	var n int
+	var sum, x, y float64
+	gust := -1.0
+	for _, s := range a.winds {
+		age := now.Sub(s.t)
+		if age < 0 || age > wxWindGust {
+			continue
+		}
+		gust = math.Max(gust, s.speed)
+		if age > wxWindSustained {
+			continue
+		}
+		n++
+		sum += s.speed
+		if s.dir >= 0 {
+			r := float64(s.dir) * math.Pi / 180.0
+			x += s.speed * math.Sin(r)
+			y += s.speed * math.Cos(r)
+			w.WindDir = s.dir
+		}
+	}
+	if gust >= 0.0 {
+		w.WindGust = int(math.Round(gust))
+	}
+	if n > 0 {
+		w.WindSpeed = int(math.Round(sum / float64(n)))
+		if x != 0 || y != 0 {
+			w.WindDir = (int(math.Round(math.Atan2(x, y)*180.0/math.Pi)) + 360) % 360
+		}
+	}
+
+	if len(a.rains) > 0 && now.Sub(a.rains[len(a.rains)-1].t) <= a.stale() {
+		loc := a.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		local := now.In(loc)
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+		w.RainLastHour = round(a.total-a.rainAt(now.Add(-wxRainHour)), 2)
+		w.RainLast24Hours = round(a.total-a.rainAt(now.Add(-wxRainDay)), 2)
+		w.RainToday = round(a.total-a.rainAt(midnight), 2)
+	}
+
+	return
+}
+
+// rainAt returns the accumulated rain at time t.  If there are no
+// samples that old then the oldest one is used.
+func (a *WxAggregator) rainAt(t time.Time) float64 {
+	total := a.rains[0].total
+	for _, r := range a.rains {
+		if r.t.After(t) {
+			break
+		}
+		total = r.total
+	}
+
+	return total
+}
+
+func (a *WxAggregator) now() time.Time {
+	if a.Now == nil {
+		return time.Now()
+	}
+	return a.Now()
+}
+
+func (a *WxAggregator) rainPerTip() float64 {
+	if a.RainPerTip > 0 {
+		return a.RainPerTip
+	}
+	return DefaultWxRainPerTip
+}
+
+func (a *WxAggregator) stale() time.Duration {
+	if a.Stale > 0 {
+		return a.Stale
+	}
+	return DefaultWxStale
+}
+
+// pruneWx removes samples older than t, keeping the newest one at or
+// before t so the start of the window remains known.
+func pruneWx[T any](s []T, t time.Time, ts func(T) time.Time) []T {
+	i := 0
+	for i+1 < len(s) && !ts(s[i+1]).After(t) {
+		i++
+	}
+
+	return s[i:]
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package aprs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWxAggregatorWind(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2016, time.November, 5, 12, 0, 0, 0, time.UTC)
+	agg := WxAggregator{Now: func() time.Time { return now }}
+
+	w := agg.Wx()
+	a.Equal(-1, w.WindSpeed, "No wind")
+	a.Equal(-1, w.WindGust, "No gust")
+	a.Equal(now, w.Timestamp, "Timestamp")
+
+	add := func(ago time.Duration, dir int, speed float64) {
+		s := WxSample{}
+		s.Zero()
+		s.Timestamp = now.Add(-ago)
+		s.WindDir, s.WindSpeed = dir, speed
+		agg.Add(s)
+	}
+	add(6*time.Minute, 90, 40) // outside gust window
+	add(4*time.Minute, 90, 25) // gust but not sustained
+	add(45*time.Second, 350, 6)
+	add(30*time.Second, 10, 10)
+	add(15*time.Second, 0, 8)
+
+	w = agg.Wx()
+	a.Equal(8, w.WindSpeed, "Sustained wind speed")
+	a.Equal(25, w.WindGust, "Peak gust")
+	a.Equal(2, w.WindDir, "Vector average wind direction")
+
+	now = now.Add(10 * time.Minute)
+	w = agg.Wx()
+	a.Equal(-1, w.WindSpeed, "Stale wind")
+	a.Equal(-1, w.WindGust, "Stale gust")
+}
+
+func TestWxAggregatorRain(t *testing.T) {
+	a := assert.New(t)
+
+	loc := time.FixedZone("EST", -5*60*60)
+	start := time.Date(2016, time.November, 5, 20, 0, 0, 0, loc)
+	now := start
+	agg := WxAggregator{RainCounter: 256, Location: loc, Now: func() time.Time { return now }}
+
+	w := agg.Wx()
+	a.Equal(-1.0, w.RainLastHour, "No rain")
+
+	// One tip every 10 minutes for 6 hours, spanning midnight, with
+	// the counter rolling over after an hour.
+	count := 250
+	for i := 0; i <= 36; i++ {
+		now = start.Add(time.Duration(i) * 10 * time.Minute)
+		s := WxSample{}
+		s.Zero()
+		s.RainCount = count
+		agg.Add(s)
+		count = (count + 1) % agg.RainCounter
+	}
+
+	w = agg.Wx()
+	a.Equal(0.06, w.RainLastHour, "Rain last hour")
+	a.Equal(0.36, w.RainLast24Hours, "Rain last 24 hours")
+	a.Equal(0.12, w.RainToday, "Rain since midnight")
+	a.Equal(-100, w.Temp, "Missing temperature")
+}
+
+func TestWxAggregatorRainReset(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2016, time.November, 5, 12, 0, 0, 0, time.UTC)
+	agg := WxAggregator{Now: func() time.Time { return now }}
+
+	// Without a known modulus a decrease can't be recovered, so counting
+	// starts again from the new value.
+	for i, count := range []int{100, 102, 7, 9} {
+		now = now.Add(time.Duration(i) * time.Minute)
+		s := WxSample{}
+		s.Zero()
+		s.RainCount = count
+		agg.Add(s)
+	}
+
+	w := agg.Wx()
+	a.Equal(0.04, w.RainLastHour, "Rain last hour")
+}
+
+func TestWxAggregatorLatest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2016, time.November, 5, 12, 0, 0, 0, time.UTC)
+	agg := WxAggregator{Now: func() time.Time { return now }}
+
+	s := WxSample{}
+	s.Zero()
+	s.Temp, s.Humidity, s.Altimeter = 68, 45, 29.92
+	agg.Add(s)
+	s.Zero()
+	s.Temp = 70
+	agg.Add(s)
+
+	w := agg.Wx()
+	a.Equal(70, w.Temp, "Latest temperature")
+	a.Equal(45, w.Humidity, "Latest humidity")
+	a.Equal(29.92, w.Altimeter, "Latest altimeter")
+	a.Equal(-1, w.SolarRad, "Missing solar radiation")
+
+	now = now.Add(DefaultWxStale + time.Second)
+	w = agg.Wx()
+	a.Equal(-100, w.Temp, "Stale temperature")
+}